@@ -1,13 +1,170 @@
 package config
 
+import (
+	"time"
+)
+
 const (
-	DefaultPort = "8060"
+	DefaultPort     = "8060"
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+	TopGenresCount  = 3
 )
 
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Config holds every setting of the application. It is loaded once at
+// startup by Load and provided to the constructors that need it.
+type Config struct {
+	// Env is "development" or "production". Insecure defaults are only
+	// accepted in development.
+	Env      string         `yaml:"env"`
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Mail     MailConfig     `yaml:"mail"`
+	Trash    TrashConfig    `yaml:"trash"`
+	Storage  StorageConfig  `yaml:"storage"`
+}
+
+type ServerConfig struct {
+	Port string `yaml:"port"`
+	// BaseURL is where clients reach the server; links in emails and
+	// locally stored images point here.
+	BaseURL      string        `yaml:"base_url"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// ShutdownDelay is how long the server keeps serving after readiness
+	// starts failing, so load balancers stop routing to it first.
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+	// ShutdownTimeout is how long in-flight requests may take to finish
+	// before their connections are closed.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	DBName   string `yaml:"name"`
+}
+
+type AuthConfig struct {
+	JWTSecret                  string        `yaml:"jwt_secret"`
+	AccessTokenLifespan        time.Duration `yaml:"access_token_lifespan"`
+	RefreshTokenLifespan       time.Duration `yaml:"refresh_token_lifespan"`
+	PasswordResetLifespan      time.Duration `yaml:"password_reset_lifespan"`
+	EmailVerificationLifespan  time.Duration `yaml:"email_verification_lifespan"`
+	VerificationResendInterval time.Duration `yaml:"verification_resend_interval"`
+	// RevocationStore is "postgres" or "memory", which only suits a single
+	// replica.
+	RevocationStore string `yaml:"revocation_store"`
+}
+
+type MailConfig struct {
+	// Driver is "log", which writes messages to LogFile or the process log,
+	// or "smtp".
+	Driver  string     `yaml:"driver"`
+	LogFile string     `yaml:"log_file"`
+	From    string     `yaml:"from"`
+	SMTP    SMTPConfig `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type TrashConfig struct {
+	// RetentionDays is how long deleted movies are kept; 0 keeps them
+	// forever.
+	RetentionDays int           `yaml:"retention_days"`
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+type StorageConfig struct {
+	// Driver is "local", which keeps files in MediaDir, or "s3".
+	Driver      string `yaml:"driver"`
+	MaxUploadMB int    `yaml:"max_upload_mb"`
+	MediaDir    string `yaml:"media_dir"`
+	// MediaBaseURL defaults to the server's base URL followed by /media.
+	MediaBaseURL string   `yaml:"media_base_url"`
+	S3           S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	UseSSL    bool   `yaml:"use_ssl"`
+	PathStyle bool   `yaml:"path_style"`
+	// PublicURL defaults to the endpoint followed by the bucket.
+	PublicURL string `yaml:"public_url"`
+}
+
+// Default returns the settings used for anything not configured.
+func Default() *Config {
+	return &Config{
+		Env: EnvProduction,
+		Server: ServerConfig{
+			Port:            DefaultPort,
+			BaseURL:         "http://localhost:8060",
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    60 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:     "localhost",
+			Port:     "5430",
+			User:     "postgres",
+			Password: insecureDBPassword,
+			DBName:   "movies_db",
+		},
+		Auth: AuthConfig{
+			JWTSecret:                  insecureJWTSecret,
+			AccessTokenLifespan:        15 * time.Minute,
+			RefreshTokenLifespan:       720 * time.Hour,
+			PasswordResetLifespan:      60 * time.Minute,
+			EmailVerificationLifespan:  48 * time.Hour,
+			VerificationResendInterval: 5 * time.Minute,
+			RevocationStore:            "postgres",
+		},
+		Mail: MailConfig{
+			Driver: "log",
+			From:   "no-reply@movies.local",
+			SMTP: SMTPConfig{
+				Host: "localhost",
+				Port: "587",
+			},
+		},
+		Trash: TrashConfig{
+			RetentionDays: 30,
+			PurgeInterval: 60 * time.Minute,
+		},
+		Storage: StorageConfig{
+			Driver:      "local",
+			MaxUploadMB: 10,
+			MediaDir:    "./media",
+			S3: S3Config{
+				Endpoint: "s3.amazonaws.com",
+				Bucket:   "movies",
+				UseSSL:   true,
+			},
+		},
+	}
+}
+
+// IsDevelopment reports whether insecure defaults are tolerated.
+func (c *Config) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
 }
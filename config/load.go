@@ -0,0 +1,368 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is read when no file is named by -config or CONFIG_FILE, if it
+// exists.
+const DefaultFile = "config.yaml"
+
+// The insecure defaults only work out of the box in development.
+const (
+	insecureJWTSecret  = "your-secret-key"
+	insecureDBPassword = "doston"
+	insecureS3Key      = "minioadmin"
+	minJWTSecretLength = 32
+)
+
+// setting ties a field of Config to the environment variable and the flag
+// that override it. Flags are named after the field's YAML path.
+type setting struct {
+	key   string
+	env   string
+	usage string
+	value flag.Value
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"env", "APP_ENV", "development or production", (*stringValue)(&c.Env)},
+		{"server.port", "PORT", "HTTP port", (*stringValue)(&c.Server.Port)},
+		{"server.base_url", "APP_BASE_URL", "URL clients use to reach the server", (*stringValue)(&c.Server.BaseURL)},
+		{"server.read_timeout", "SERVER_READ_TIMEOUT", "longest time to read a request, 0 for none", &durationValue{&c.Server.ReadTimeout, time.Second}},
+		{"server.write_timeout", "SERVER_WRITE_TIMEOUT", "longest time to write a response, 0 for none", &durationValue{&c.Server.WriteTimeout, time.Second}},
+		{"server.idle_timeout", "SERVER_IDLE_TIMEOUT", "how long idle keep-alive connections stay open", &durationValue{&c.Server.IdleTimeout, time.Second}},
+		{"server.shutdown_delay", "SHUTDOWN_DELAY", "time between failing readiness and draining on shutdown", &durationValue{&c.Server.ShutdownDelay, time.Second}},
+		{"server.shutdown_timeout", "SHUTDOWN_TIMEOUT", "how long in-flight requests may take to finish on shutdown", &durationValue{&c.Server.ShutdownTimeout, time.Second}},
+
+		{"database.host", "DB_HOST", "PostgreSQL host", (*stringValue)(&c.Database.Host)},
+		{"database.port", "DB_PORT", "PostgreSQL port", (*stringValue)(&c.Database.Port)},
+		{"database.user", "DB_USER", "PostgreSQL user", (*stringValue)(&c.Database.User)},
+		{"database.password", "DB_PASSWORD", "PostgreSQL password", (*stringValue)(&c.Database.Password)},
+		{"database.name", "DB_NAME", "PostgreSQL database", (*stringValue)(&c.Database.DBName)},
+
+		{"auth.jwt_secret", "JWT_SECRET", "key signing access tokens", (*stringValue)(&c.Auth.JWTSecret)},
+		{"auth.access_token_lifespan", "ACCESS_TOKEN_MINUTE_LIFESPAN", "access token lifespan", &durationValue{&c.Auth.AccessTokenLifespan, time.Minute}},
+		{"auth.refresh_token_lifespan", "REFRESH_TOKEN_HOUR_LIFESPAN", "refresh token lifespan", &durationValue{&c.Auth.RefreshTokenLifespan, time.Hour}},
+		{"auth.password_reset_lifespan", "PASSWORD_RESET_MINUTE_LIFESPAN", "password reset link lifespan", &durationValue{&c.Auth.PasswordResetLifespan, time.Minute}},
+		{"auth.email_verification_lifespan", "EMAIL_VERIFICATION_HOUR_LIFESPAN", "email verification link lifespan", &durationValue{&c.Auth.EmailVerificationLifespan, time.Hour}},
+		{"auth.verification_resend_interval", "VERIFICATION_RESEND_MINUTE_INTERVAL", "minimum time between verification emails", &durationValue{&c.Auth.VerificationResendInterval, time.Minute}},
+		{"auth.revocation_store", "TOKEN_REVOCATION_STORE", "access token denylist: postgres or memory", (*stringValue)(&c.Auth.RevocationStore)},
+
+		{"mail.driver", "MAIL_DRIVER", "mail delivery: log or smtp", (*stringValue)(&c.Mail.Driver)},
+		{"mail.log_file", "MAIL_LOG_FILE", "file the log driver writes to, stdout when empty", (*stringValue)(&c.Mail.LogFile)},
+		{"mail.from", "MAIL_FROM", "sender address", (*stringValue)(&c.Mail.From)},
+		{"mail.smtp.host", "SMTP_HOST", "SMTP host", (*stringValue)(&c.Mail.SMTP.Host)},
+		{"mail.smtp.port", "SMTP_PORT", "SMTP port", (*stringValue)(&c.Mail.SMTP.Port)},
+		{"mail.smtp.username", "SMTP_USERNAME", "SMTP user", (*stringValue)(&c.Mail.SMTP.Username)},
+		{"mail.smtp.password", "SMTP_PASSWORD", "SMTP password", (*stringValue)(&c.Mail.SMTP.Password)},
+
+		{"trash.retention_days", "TRASH_RETENTION_DAYS", "days deleted movies are kept, 0 for ever", (*intValue)(&c.Trash.RetentionDays)},
+		{"trash.purge_interval", "TRASH_PURGE_MINUTE_INTERVAL", "time between trash purges", &durationValue{&c.Trash.PurgeInterval, time.Minute}},
+
+		{"storage.driver", "STORAGE_DRIVER", "image storage: local or s3", (*stringValue)(&c.Storage.Driver)},
+		{"storage.max_upload_mb", "IMAGE_MAX_UPLOAD_MB", "largest accepted image in megabytes", (*intValue)(&c.Storage.MaxUploadMB)},
+		{"storage.media_dir", "MEDIA_DIR", "directory of the local driver", (*stringValue)(&c.Storage.MediaDir)},
+		{"storage.media_base_url", "MEDIA_BASE_URL", "URL of the local driver's files", (*stringValue)(&c.Storage.MediaBaseURL)},
+		{"storage.s3.endpoint", "S3_ENDPOINT", "S3 endpoint", (*stringValue)(&c.Storage.S3.Endpoint)},
+		{"storage.s3.region", "S3_REGION", "S3 region", (*stringValue)(&c.Storage.S3.Region)},
+		{"storage.s3.bucket", "S3_BUCKET", "S3 bucket", (*stringValue)(&c.Storage.S3.Bucket)},
+		{"storage.s3.access_key", "S3_ACCESS_KEY", "S3 access key", (*stringValue)(&c.Storage.S3.AccessKey)},
+		{"storage.s3.secret_key", "S3_SECRET_KEY", "S3 secret key", (*stringValue)(&c.Storage.S3.SecretKey)},
+		{"storage.s3.use_ssl", "S3_USE_SSL", "use HTTPS for S3", (*boolValue)(&c.Storage.S3.UseSSL)},
+		{"storage.s3.path_style", "S3_PATH_STYLE", "use path-style S3 URLs", (*boolValue)(&c.Storage.S3.PathStyle)},
+		{"storage.s3.public_url", "S3_PUBLIC_URL", "URL the bucket's objects are read from", (*stringValue)(&c.Storage.S3.PublicURL)},
+	}
+}
+
+// Load builds the configuration from the defaults, then a YAML file, then
+// environment variables, then the flags in args, each layer overriding the
+// previous one. The flags are registered on flags, which stops parsing at
+// the first argument that is not a flag; flags.Args returns the rest. The
+// result is validated.
+func Load(flags *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	file := flags.String("config", "", "YAML configuration file (default $CONFIG_FILE or "+DefaultFile+")")
+	var overrides []string
+	for _, s := range settings {
+		flags.Var(flagOverride{key: s.key, overrides: &overrides}, s.key, fmt.Sprintf("%s (env %s)", s.usage, s.env))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.loadFile(*file); err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]setting, len(settings))
+	var errs []error
+	for _, s := range settings {
+		byKey[s.key] = s
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := s.value.Set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+	for _, override := range overrides {
+		key, value, _ := strings.Cut(override, "=")
+		if err := byKey[key].value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %w", key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	cfg.applyDerivedDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.IsDevelopment() {
+		for _, problem := range cfg.insecureSettings() {
+			log.Printf("Warning: %s; this is only accepted in development", problem)
+		}
+	}
+	return cfg, nil
+}
+
+// loadFile reads name, or the file named by CONFIG_FILE, or DefaultFile if
+// it exists. Unknown keys are rejected so that typos do not go unnoticed.
+func (c *Config) loadFile(name string) error {
+	if name == "" {
+		name = os.Getenv("CONFIG_FILE")
+	}
+	if name == "" {
+		name = DefaultFile
+		if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// applyDerivedDefaults fills the settings whose default depends on others.
+func (c *Config) applyDerivedDefaults() {
+	c.Server.BaseURL = strings.TrimRight(c.Server.BaseURL, "/")
+	if c.Storage.MediaBaseURL == "" {
+		c.Storage.MediaBaseURL = c.Server.BaseURL + "/media"
+	}
+	c.Storage.MediaBaseURL = strings.TrimRight(c.Storage.MediaBaseURL, "/")
+
+	if c.Storage.S3.PublicURL == "" {
+		scheme := "https"
+		if !c.Storage.S3.UseSSL {
+			scheme = "http"
+		}
+		c.Storage.S3.PublicURL = fmt.Sprintf("%s://%s/%s", scheme, c.Storage.S3.Endpoint, c.Storage.S3.Bucket)
+	}
+	c.Storage.S3.PublicURL = strings.TrimRight(c.Storage.S3.PublicURL, "/")
+}
+
+// Validate reports every invalid setting at once. Outside development it
+// also refuses insecure defaults.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Env == EnvDevelopment || c.Env == EnvProduction, "env must be %q or %q", EnvDevelopment, EnvProduction)
+	check(validPort(c.Server.Port), "server.port must be a port number")
+	check(validURL(c.Server.BaseURL), "server.base_url must be an http or https URL")
+	check(c.Server.ReadTimeout >= 0 && c.Server.WriteTimeout >= 0 && c.Server.IdleTimeout >= 0, "server timeouts must not be negative")
+	check(c.Server.ShutdownDelay >= 0, "server.shutdown_delay must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+
+	check(c.Database.Host != "", "database.host is required")
+	check(validPort(c.Database.Port), "database.port must be a port number")
+	check(c.Database.User != "", "database.user is required")
+	check(c.Database.DBName != "", "database.name is required")
+
+	check(c.Auth.JWTSecret != "", "auth.jwt_secret is required")
+	check(c.Auth.AccessTokenLifespan > 0, "auth.access_token_lifespan must be positive")
+	check(c.Auth.RefreshTokenLifespan > 0, "auth.refresh_token_lifespan must be positive")
+	check(c.Auth.PasswordResetLifespan > 0, "auth.password_reset_lifespan must be positive")
+	check(c.Auth.EmailVerificationLifespan > 0, "auth.email_verification_lifespan must be positive")
+	check(c.Auth.VerificationResendInterval >= 0, "auth.verification_resend_interval must not be negative")
+	check(c.Auth.RevocationStore == "postgres" || c.Auth.RevocationStore == "memory", "auth.revocation_store must be \"postgres\" or \"memory\"")
+
+	check(c.Mail.Driver == "log" || c.Mail.Driver == "smtp", "mail.driver must be \"log\" or \"smtp\"")
+	check(c.Mail.From != "", "mail.from is required")
+	if c.Mail.Driver == "smtp" {
+		check(c.Mail.SMTP.Host != "", "mail.smtp.host is required by the smtp driver")
+		check(validPort(c.Mail.SMTP.Port), "mail.smtp.port must be a port number")
+	}
+
+	check(c.Trash.RetentionDays >= 0, "trash.retention_days must not be negative")
+	check(c.Trash.PurgeInterval > 0, "trash.purge_interval must be positive")
+
+	check(c.Storage.Driver == "local" || c.Storage.Driver == "s3", "storage.driver must be \"local\" or \"s3\"")
+	check(c.Storage.MaxUploadMB > 0, "storage.max_upload_mb must be positive")
+	switch c.Storage.Driver {
+	case "local":
+		check(c.Storage.MediaDir != "", "storage.media_dir is required by the local driver")
+		check(validURL(c.Storage.MediaBaseURL), "storage.media_base_url must be an http or https URL")
+	case "s3":
+		check(c.Storage.S3.Endpoint != "", "storage.s3.endpoint is required by the s3 driver")
+		check(c.Storage.S3.Bucket != "", "storage.s3.bucket is required by the s3 driver")
+		check(c.Storage.S3.AccessKey != "" && c.Storage.S3.SecretKey != "", "storage.s3.access_key and storage.s3.secret_key are required by the s3 driver")
+		check(validURL(c.Storage.S3.PublicURL), "storage.s3.public_url must be an http or https URL")
+	}
+
+	if !c.IsDevelopment() {
+		for _, problem := range c.insecureSettings() {
+			errs = append(errs, fmt.Errorf("%s; set env to %q to allow it", problem, EnvDevelopment))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// insecureSettings describes the settings left at values that are fine for
+// a local setup but must not reach production.
+func (c *Config) insecureSettings() []string {
+	var problems []string
+	if c.Auth.JWTSecret == insecureJWTSecret {
+		problems = append(problems, "auth.jwt_secret is the default")
+	} else if len(c.Auth.JWTSecret) < minJWTSecretLength {
+		problems = append(problems, fmt.Sprintf("auth.jwt_secret is shorter than %d bytes", minJWTSecretLength))
+	}
+	if c.Database.Password == insecureDBPassword || c.Database.Password == "" {
+		problems = append(problems, "database.password is the default or empty")
+	}
+	if c.Storage.Driver == "s3" && (c.Storage.S3.AccessKey == insecureS3Key || c.Storage.S3.SecretKey == insecureS3Key) {
+		problems = append(problems, "storage.s3 uses the default MinIO credentials")
+	}
+	return problems
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n < 65536
+}
+
+func validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// flagOverride records a flag so it can be applied after the environment,
+// although flags are parsed first to find the configuration file.
+type flagOverride struct {
+	key       string
+	overrides *[]string
+}
+
+func (f flagOverride) String() string { return "" }
+
+func (f flagOverride) Set(value string) error {
+	*f.overrides = append(*f.overrides, f.key+"="+value)
+	return nil
+}
+
+type stringValue string
+
+func (v *stringValue) String() string { return string(*v) }
+
+func (v *stringValue) Set(value string) error {
+	*v = stringValue(value)
+	return nil
+}
+
+type intValue int
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+func (v *intValue) Set(value string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("%q is not a whole number", value)
+	}
+	*v = intValue(n)
+	return nil
+}
+
+type boolValue bool
+
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+func (v *boolValue) Set(value string) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("%q is not true or false", value)
+	}
+	*v = boolValue(b)
+	return nil
+}
+
+// durationValue accepts a Go duration such as "90m", or a plain number in
+// unit, which is what the environment variables have always used.
+type durationValue struct {
+	d    *time.Duration
+	unit time.Duration
+}
+
+func (v *durationValue) String() string {
+	if v.d == nil {
+		return ""
+	}
+	return v.d.String()
+}
+
+func (v *durationValue) Set(value string) error {
+	value = strings.TrimSpace(value)
+	if n, err := strconv.Atoi(value); err == nil {
+		*v.d = time.Duration(n) * v.unit
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%q is neither a number of %s nor a duration", value, unitName(v.unit))
+	}
+	*v.d = d
+	return nil
+}
+
+func unitName(unit time.Duration) string {
+	switch unit {
+	case time.Hour:
+		return "hours"
+	case time.Minute:
+		return "minutes"
+	default:
+		return "seconds"
+	}
+}
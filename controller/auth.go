@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/dostonshernazarov/movies-app/middleware"
 	"github.com/dostonshernazarov/movies-app/models"
 	"github.com/dostonshernazarov/movies-app/services"
 	"github.com/gin-gonic/gin"
@@ -20,7 +23,7 @@ func NewAuthController(authService *services.AuthService) *AuthController {
 }
 
 // @Summary Register a new user
-// @Description Register a new user with username, password, and email
+// @Description Register a new user with username, password, and email. A verification link is emailed; until it is opened the account is read-only.
 // @Accept json
 // @Produce json
 // @Tags Auth
@@ -47,10 +50,12 @@ func (c *AuthController) Register(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusCreated, models.UserRegisterResponse{
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          user.Role,
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     user.UpdatedAt.Format(time.RFC3339),
 	})
 }
 
@@ -70,17 +75,207 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	token, user, err := c.AuthService.Login(request.Username, request.Password)
+	tokens, user, err := c.AuthService.Login(request.Username, request.Password)
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, models.AuthResponse{
-		Token:     token,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+		Token:         tokens.AccessToken,
+		RefreshToken:  tokens.RefreshToken,
+		ExpiresIn:     tokens.ExpiresIn,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          user.Role,
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     user.UpdatedAt.Format(time.RFC3339),
 	})
 }
+
+// @Summary Refresh an access token
+// @Description Exchange a refresh token for a new access and refresh token pair. The presented refresh token is revoked; reusing it revokes the whole session.
+// @Accept json
+// @Produce json
+// @Tags Auth
+// @Param token body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} models.TokenResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /auth/refresh [post]
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var request models.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	tokens, _, err := c.AuthService.Refresh(request.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	})
+}
+
+// @Summary Logout
+// @Security BearerAuth
+// @Description Revoke the current access token and the refresh tokens of its session
+// @Accept json
+// @Produce json
+// @Tags Auth
+// @Success 200 {object} models.MessageResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /auth/logout [post]
+func (c *AuthController) Logout(ctx *gin.Context) {
+	if err := c.AuthService.Logout(middleware.GetClaims(ctx)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Logged out successfully"})
+}
+
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the address is registered.
+// @Accept json
+// @Produce json
+// @Tags Auth
+// @Param request body models.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /auth/password/forgot [post]
+func (c *AuthController) ForgotPassword(ctx *gin.Context) {
+	var request models.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := c.AuthService.ForgotPassword(request.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, models.MessageResponse{Message: "If the address is registered, a reset link has been sent"})
+}
+
+// @Summary Reset a password
+// @Description Set a new password with a reset token. All existing sessions of the user are revoked.
+// @Accept json
+// @Produce json
+// @Tags Auth
+// @Param request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /auth/password/reset [post]
+func (c *AuthController) ResetPassword(ctx *gin.Context) {
+	var request models.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := c.AuthService.ResetPassword(request.Token, request.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Password has been reset"})
+}
+
+// @Summary Verify an email address
+// @Description Mark the email address from a verification link as verified
+// @Produce json
+// @Tags Auth
+// @Param token query string true "Verification token"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /auth/verify [get]
+func (c *AuthController) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "token is required"})
+		return
+	}
+
+	if _, err := c.AuthService.VerifyEmail(token); err != nil {
+		if errors.Is(err, services.ErrInvalidVerification) {
+			ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Email address verified"})
+}
+
+// @Summary Resend the verification email
+// @Security BearerAuth
+// @Description Send a new verification link to the authenticated user. Limited to one email per VERIFICATION_RESEND_MINUTE_INTERVAL.
+// @Produce json
+// @Tags Auth
+// @Success 202 {object} models.MessageResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /auth/verify/resend [post]
+func (c *AuthController) ResendVerification(ctx *gin.Context) {
+	err := c.AuthService.ResendVerification(middleware.GetUserID(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAlreadyVerified):
+			ctx.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, services.ErrVerificationThrottled):
+			ctx.JSON(http.StatusTooManyRequests, models.ErrorResponse{Error: err.Error()})
+		default:
+			respondUserError(ctx, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, models.MessageResponse{Message: "Verification email sent"})
+}
+
+// @Summary Revoke all of a user's sessions
+// @Security BearerAuth
+// @Description Invalidate every access and refresh token issued to the user so far. Admin only.
+// @Accept json
+// @Produce json
+// @Tags Admin
+// @Param id path string true "User ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/admin/users/{id}/sessions/revoke [post]
+func (c *AuthController) RevokeUserSessions(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	if err := c.AuthService.RevokeAllSessions(uint(id)); err != nil {
+		respondUserError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "All sessions revoked"})
+}
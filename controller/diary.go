@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type DiaryController struct {
+	DiaryService *services.DiaryService
+}
+
+func NewDiaryController(diaryService *services.DiaryService) *DiaryController {
+	return &DiaryController{
+		DiaryService: diaryService,
+	}
+}
+
+// @Summary Get my diary
+// @Security BearerAuth
+// @Description Get a page of the authenticated user's logged viewings, most recent first
+// @Accept json
+// @Produce json
+// @Tags Diary
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.Diary
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/me/diary [get]
+func (c *DiaryController) GetDiary(ctx *gin.Context) {
+	var page models.PageQuery
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	entries, total, err := c.DiaryService.ListDiary(middleware.GetUserID(ctx), &page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	responses := make([]models.DiaryEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = toDiaryEntryResponse(entry)
+	}
+
+	ctx.JSON(http.StatusOK, models.Diary{
+		Entries:    responses,
+		Pagination: newPagination(page, total),
+	})
+}
+
+// @Summary Get a diary entry
+// @Security BearerAuth
+// @Description Get one of the authenticated user's diary entries
+// @Accept json
+// @Produce json
+// @Tags Diary
+// @Param entryId path string true "Diary entry ID"
+// @Success 200 {object} models.DiaryEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/me/diary/{entryId} [get]
+func (c *DiaryController) GetDiaryEntry(ctx *gin.Context) {
+	entry, ok := c.loadDiaryEntry(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toDiaryEntryResponse(entry))
+}
+
+// @Summary Log a viewing
+// @Security BearerAuth
+// @Description Record that the authenticated user watched a movie. watched_on defaults to today.
+// @Accept json
+// @Produce json
+// @Tags Diary
+// @Param entry body models.DiaryCreateRequest true "Viewing details"
+// @Success 201 {object} models.DiaryEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/me/diary [post]
+func (c *DiaryController) LogViewing(ctx *gin.Context) {
+	var request models.DiaryCreateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	entry := models.DiaryEntry{
+		UserID:  middleware.GetUserID(ctx),
+		MovieID: request.MovieID,
+	}
+	applyDiaryEntryRequest(&entry, request.DiaryEntryRequest)
+
+	if err := c.DiaryService.LogViewing(&entry); err != nil {
+		respondDiaryError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toDiaryEntryResponse(entry))
+}
+
+// @Summary Update a diary entry
+// @Security BearerAuth
+// @Description Change the date, rewatch flag or notes of a logged viewing
+// @Accept json
+// @Produce json
+// @Tags Diary
+// @Param entryId path string true "Diary entry ID"
+// @Param entry body models.DiaryEntryRequest true "Viewing details"
+// @Success 200 {object} models.DiaryEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/me/diary/{entryId} [put]
+func (c *DiaryController) UpdateDiaryEntry(ctx *gin.Context) {
+	entry, ok := c.loadDiaryEntry(ctx)
+	if !ok {
+		return
+	}
+
+	var request models.DiaryEntryRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if request.WatchedOn == "" {
+		// Keep the logged date unless a new one is given.
+		request.WatchedOn = entry.WatchedOn.Format(time.DateOnly)
+	}
+	applyDiaryEntryRequest(&entry, request)
+
+	if err := c.DiaryService.UpdateDiaryEntry(&entry); err != nil {
+		respondDiaryError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toDiaryEntryResponse(entry))
+}
+
+// @Summary Delete a diary entry
+// @Security BearerAuth
+// @Description Remove a logged viewing from the authenticated user's diary
+// @Accept json
+// @Produce json
+// @Tags Diary
+// @Param entryId path string true "Diary entry ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/me/diary/{entryId} [delete]
+func (c *DiaryController) DeleteDiaryEntry(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "entryId")
+	if !ok {
+		return
+	}
+
+	if err := c.DiaryService.DeleteDiaryEntry(middleware.GetUserID(ctx), id); err != nil {
+		respondDiaryError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Diary entry deleted successfully"})
+}
+
+func (c *DiaryController) loadDiaryEntry(ctx *gin.Context) (models.DiaryEntry, bool) {
+	id, ok := parseIDParam(ctx, "entryId")
+	if !ok {
+		return models.DiaryEntry{}, false
+	}
+
+	entry, err := c.DiaryService.GetDiaryEntry(middleware.GetUserID(ctx), id)
+	if err != nil {
+		respondDiaryError(ctx, err)
+		return models.DiaryEntry{}, false
+	}
+	return entry, true
+}
+
+func applyDiaryEntryRequest(entry *models.DiaryEntry, request models.DiaryEntryRequest) {
+	entry.Rewatch = request.Rewatch
+	entry.Notes = request.Notes
+	if request.WatchedOn != "" {
+		// Already validated by the datetime binding.
+		entry.WatchedOn, _ = time.Parse(time.DateOnly, request.WatchedOn)
+	}
+}
+
+func respondDiaryError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMovieNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+	case errors.Is(err, services.ErrDiaryEntryNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Diary entry not found"})
+	case errors.Is(err, services.ErrFutureWatchDate):
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+func toDiaryEntryResponse(entry models.DiaryEntry) models.DiaryEntryResponse {
+	return models.DiaryEntryResponse{
+		ID:        entry.ID,
+		WatchedOn: entry.WatchedOn.Format(time.DateOnly),
+		Rewatch:   entry.Rewatch,
+		Notes:     entry.Notes,
+		CreatedAt: entry.CreatedAt,
+		UpdatedAt: entry.UpdatedAt,
+		Movie:     toMovieResponse(entry.Movie),
+	}
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/gin-gonic/gin"
+)
+
+// movieETag is the strong entity tag of a movie version.
+func movieETag(movie models.Movie) string {
+	return fmt.Sprintf(`"%d-%d"`, movie.ID, movie.Version)
+}
+
+// checkIfMatch makes a write conditional on the client holding the current
+// version. It writes 428 when If-Match is missing and 412 when it does not
+// match, and returns false in both cases.
+func checkIfMatch(ctx *gin.Context, etag string) bool {
+	header := ctx.GetHeader("If-Match")
+	if header == "" {
+		ctx.JSON(http.StatusPreconditionRequired, models.ErrorResponse{Error: "If-Match header is required"})
+		return false
+	}
+	// If-Match uses strong comparison, so weak tags never match.
+	if !etagListContains(header, etag, false) {
+		respondPreconditionFailed(ctx)
+		return false
+	}
+	return true
+}
+
+// notModified answers a conditional GET. It writes 304 and returns true when
+// If-None-Match matches etag.
+func notModified(ctx *gin.Context, etag string) bool {
+	header := ctx.GetHeader("If-None-Match")
+	if header == "" || !etagListContains(header, etag, true) {
+		return false
+	}
+	ctx.Status(http.StatusNotModified)
+	return true
+}
+
+func respondPreconditionFailed(ctx *gin.Context) {
+	ctx.JSON(http.StatusPreconditionFailed, models.ErrorResponse{Error: "The movie has been modified, fetch it again and retry"})
+}
+
+func etagListContains(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMovieETag(t *testing.T) {
+	movie := models.Movie{Version: 3}
+	movie.ID = 42
+	if got, want := movieETag(movie), `"42-3"`; got != want {
+		t.Errorf("movieETag() = %s, want %s", got, want)
+	}
+}
+
+// conditionalContext returns a test context for a request with the given
+// header, which is not set when value is empty.
+func conditionalContext(header, value string) (*gin.Context, *httptest.ResponseRecorder) {
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/api/movies/42", nil)
+	if value != "" {
+		ctx.Request.Header.Set(header, value)
+	}
+	return ctx, recorder
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	const etag = `"42-3"`
+
+	tests := []struct {
+		name       string
+		ifMatch    string
+		want       bool
+		wantStatus int
+	}{
+		{"missing", "", false, http.StatusPreconditionRequired},
+		{"current", `"42-3"`, true, http.StatusOK},
+		{"stale", `"42-2"`, false, http.StatusPreconditionFailed},
+		{"other movie", `"43-3"`, false, http.StatusPreconditionFailed},
+		{"unquoted", `42-3`, false, http.StatusPreconditionFailed},
+		{"weak", `W/"42-3"`, false, http.StatusPreconditionFailed},
+		{"list containing current", `"42-2", "42-3"`, true, http.StatusOK},
+		{"list without current", `"42-1","42-2"`, false, http.StatusPreconditionFailed},
+		{"wildcard", `*`, true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, recorder := conditionalContext("If-Match", tt.ifMatch)
+			if got := checkIfMatch(ctx, etag); got != tt.want {
+				t.Errorf("checkIfMatch(%q) = %v, want %v", tt.ifMatch, got, tt.want)
+			}
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("checkIfMatch(%q) status = %d, want %d", tt.ifMatch, recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `"42-3"`
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{"missing", "", false},
+		{"current", `"42-3"`, true},
+		{"weak current", `W/"42-3"`, true},
+		{"stale", `"42-2"`, false},
+		{"list containing current", `"42-2", W/"42-3"`, true},
+		{"wildcard", `*`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := conditionalContext("If-None-Match", tt.ifNoneMatch)
+			if got := notModified(ctx, etag); got != tt.want {
+				t.Errorf("notModified(%q) = %v, want %v", tt.ifNoneMatch, got, tt.want)
+			}
+			if tt.want && ctx.Writer.Status() != http.StatusNotModified {
+				t.Errorf("notModified(%q) status = %d, want %d", tt.ifNoneMatch, ctx.Writer.Status(), http.StatusNotModified)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// movieExportColumns are the column headers of CSV and XLSX exports, in
+// the order movieExportRow returns the values.
+var movieExportColumns = []string{
+	"id", "title", "director", "year", "plot", "genres", "rating",
+	"average_rating", "vote_count", "user_id", "created_at", "updated_at",
+}
+
+// movieExporter writes exported movies in one file format. Close finishes
+// the document and must be called even after a failed Write.
+type movieExporter interface {
+	Write(movie models.MovieExport) error
+	Close() error
+}
+
+type exportFormat struct {
+	ContentType string
+	Extension   string
+	New         func(w io.Writer) (movieExporter, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv": {
+		ContentType: "text/csv; charset=utf-8",
+		Extension:   "csv",
+		New:         newCSVExporter,
+	},
+	"ndjson": {
+		ContentType: "application/x-ndjson",
+		Extension:   "ndjson",
+		New:         newNDJSONExporter,
+	},
+	"xlsx": {
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		Extension:   "xlsx",
+		New:         newXLSXExporter,
+	},
+}
+
+// @Summary Export movies
+// @Security BearerAuth
+// @Description Download every movie matching the listing filters as CSV, JSON Lines or an Excel workbook. Rows are streamed as they are read, so a failure midway leaves the file truncated.
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce json
+// @Tags Movies
+// @Param format query string false "File format: csv (default), ndjson or xlsx"
+// @Param genre query string false "Genre name or slug"
+// @Param director query string false "Director (partial match)"
+// @Param min_year query int false "Minimum release year"
+// @Param max_year query int false "Maximum release year"
+// @Param min_rating query number false "Minimum average review rating"
+// @Param max_rating query number false "Maximum average review rating"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (e.g. -rating,year)"
+// @Success 200 {file} file
+// @Header 200 {string} Content-Disposition "attachment; filename=movies-<timestamp>.<format>"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/movies/export [get]
+func (c *MovieController) ExportMovies(ctx *gin.Context) {
+	var query models.MovieExportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if query.Format == "" {
+		query.Format = "csv"
+	}
+	format := exportFormats[query.Format]
+
+	// Headers are sent with the first row, so errors found before it, such
+	// as an invalid sort, can still be reported as JSON.
+	var exporter movieExporter
+	start := func() error {
+		if exporter != nil {
+			return nil
+		}
+		filename := fmt.Sprintf("movies-%s.%s", time.Now().UTC().Format("20060102-150405"), format.Extension)
+		ctx.Header("Content-Type", format.ContentType)
+		ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		ctx.Status(http.StatusOK)
+
+		var err error
+		exporter, err = format.New(newDeadlineWriter(ctx.Writer))
+		return err
+	}
+
+	err := c.MovieService.ExportMovies(&query.MovieListQuery, func(movie models.Movie) error {
+		if err := start(); err != nil {
+			return err
+		}
+		return exporter.Write(toMovieExport(movie))
+	})
+	if err == nil {
+		err = start()
+	}
+	if exporter != nil {
+		err = errors.Join(err, exporter.Close())
+	}
+
+	switch {
+	case err == nil:
+	case ctx.Writer.Written():
+		// The status is already sent; all that is left is to log the error.
+		_ = ctx.Error(err)
+	case errors.Is(err, services.ErrInvalidSort), errors.Is(err, services.ErrInvalidGenre):
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+// exportWriteTimeout bounds each write of an export. Large exports can
+// outlast server.write_timeout, so the deadline is pushed back before every
+// write instead, and only a client that stops reading is cut off.
+const exportWriteTimeout = 30 * time.Second
+
+type deadlineWriter struct {
+	w          io.Writer
+	controller *http.ResponseController
+}
+
+func newDeadlineWriter(w http.ResponseWriter) io.Writer {
+	return &deadlineWriter{w: w, controller: http.NewResponseController(w)}
+}
+
+func (w *deadlineWriter) Write(p []byte) (int, error) {
+	// Writers that do not support deadlines are written to as they are.
+	_ = w.controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	return w.w.Write(p)
+}
+
+func toMovieExport(movie models.Movie) models.MovieExport {
+	genres := make([]string, len(movie.Genres))
+	for i, genre := range movie.Genres {
+		genres[i] = genre.Name
+	}
+
+	return models.MovieExport{
+		ID:            movie.ID,
+		Title:         movie.Title,
+		Director:      movie.Director,
+		Year:          movie.Year,
+		Plot:          movie.Plot,
+		Genres:        genres,
+		Rating:        movie.Rating,
+		AverageRating: movie.AverageRating,
+		VoteCount:     movie.VoteCount,
+		UserID:        movie.UserID,
+		CreatedAt:     movie.CreatedAt,
+		UpdatedAt:     movie.UpdatedAt,
+	}
+}
+
+// movieExportRow returns the values of a movie in movieExportColumns order.
+func movieExportRow(movie models.MovieExport) []interface{} {
+	return []interface{}{
+		movie.ID, movie.Title, movie.Director, movie.Year, movie.Plot,
+		strings.Join(movie.Genres, ", "), movie.Rating, movie.AverageRating,
+		movie.VoteCount, movie.UserID, movie.CreatedAt, movie.UpdatedAt,
+	}
+}
+
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+func newCSVExporter(w io.Writer) (movieExporter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(movieExportColumns); err != nil {
+		return nil, err
+	}
+	return &csvExporter{writer: writer}, nil
+}
+
+func (e *csvExporter) Write(movie models.MovieExport) error {
+	row := movieExportRow(movie)
+	record := make([]string, len(row))
+	for i, value := range row {
+		switch value := value.(type) {
+		case string:
+			record[i] = value
+		case float32:
+			record[i] = strconv.FormatFloat(float64(value), 'f', -1, 32)
+		case time.Time:
+			record[i] = value.UTC().Format(time.RFC3339)
+		default:
+			record[i] = fmt.Sprint(value)
+		}
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExporter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type ndjsonExporter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newNDJSONExporter(w io.Writer) (movieExporter, error) {
+	buffer := bufio.NewWriter(w)
+	return &ndjsonExporter{buffer: buffer, encoder: json.NewEncoder(buffer)}, nil
+}
+
+func (e *ndjsonExporter) Write(movie models.MovieExport) error {
+	return e.encoder.Encode(movie)
+}
+
+func (e *ndjsonExporter) Close() error {
+	return e.buffer.Flush()
+}
+
+// xlsxExporter streams rows into a single worksheet. The workbook itself is
+// a zip archive, so it is only written out on Close.
+type xlsxExporter struct {
+	w         io.Writer
+	file      *excelize.File
+	stream    *excelize.StreamWriter
+	dateStyle int
+	row       int
+}
+
+func newXLSXExporter(w io.Writer) (movieExporter, error) {
+	file := excelize.NewFile()
+	exporter := &xlsxExporter{w: w, file: file, row: 1}
+
+	var err error
+	if exporter.stream, err = file.NewStreamWriter("Sheet1"); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if exporter.dateStyle, err = file.NewStyle(&excelize.Style{NumFmt: 22}); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	header := make([]interface{}, len(movieExportColumns))
+	for i, column := range movieExportColumns {
+		header[i] = column
+	}
+	if err := exporter.setRow(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return exporter, nil
+}
+
+func (e *xlsxExporter) Write(movie models.MovieExport) error {
+	row := movieExportRow(movie)
+	for i, value := range row {
+		if t, ok := value.(time.Time); ok {
+			row[i] = excelize.Cell{StyleID: e.dateStyle, Value: t.UTC()}
+		}
+	}
+	return e.setRow(row)
+}
+
+func (e *xlsxExporter) setRow(values []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	e.row++
+	return e.stream.SetRow(cell, values)
+}
+
+func (e *xlsxExporter) Close() error {
+	defer e.file.Close()
+
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := e.file.WriteTo(e.w)
+	return err
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type GenreController struct {
+	GenreService *services.GenreService
+}
+
+func NewGenreController(genreService *services.GenreService) *GenreController {
+	return &GenreController{
+		GenreService: genreService,
+	}
+}
+
+// @Summary List genres
+// @Security BearerAuth
+// @Description Get every canonical genre with the number of movies in it
+// @Accept json
+// @Produce json
+// @Tags Genres
+// @Success 200 {object} models.Genres
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/genres [get]
+func (c *GenreController) GetGenres(ctx *gin.Context) {
+	genres, err := c.GenreService.ListGenres()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	responses := make([]models.GenreWithCountResponse, len(genres))
+	for i, genre := range genres {
+		responses[i] = models.GenreWithCountResponse{
+			ID:         genre.ID,
+			Name:       genre.Name,
+			Slug:       genre.Slug,
+			MovieCount: genre.MovieCount,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, models.Genres{Genres: responses})
+}
+
+func toGenres(names []string) []models.Genre {
+	genres := make([]models.Genre, len(names))
+	for i, name := range names {
+		genres[i] = models.Genre{Name: name}
+	}
+	return genres
+}
+
+func toGenreResponses(genres []models.Genre) []models.GenreResponse {
+	responses := make([]models.GenreResponse, len(genres))
+	for i, genre := range genres {
+		responses[i] = models.GenreResponse{
+			ID:   genre.ID,
+			Name: genre.Name,
+			Slug: genre.Slug,
+		}
+	}
+	return responses
+}
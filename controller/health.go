@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	Readiness *services.Readiness
+	Registry  *services.HealthRegistry
+}
+
+func NewHealthController(readiness *services.Readiness, registry *services.HealthRegistry) *HealthController {
+	return &HealthController{
+		Readiness: readiness,
+		Registry:  registry,
+	}
+}
+
+// @Summary Liveness probe
+// @Description Reports that the process is running and serving requests. It does not check dependencies, so an outage of one never gets the instance restarted.
+// @Produce json
+// @Tags Health
+// @Success 200 {object} models.HealthReport
+// @Router /healthz [get]
+func (c *HealthController) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, models.HealthReport{Status: models.HealthStatusOK})
+}
+
+// @Summary Readiness probe
+// @Description Reports whether the instance should receive traffic. It checks the database connection, that migrations are current and every other registered dependency, with the outcome and latency of each. It fails without running the checks once the server starts shutting down.
+// @Produce json
+// @Tags Health
+// @Success 200 {object} models.HealthReport
+// @Failure 503 {object} models.HealthReport
+// @Router /readyz [get]
+func (c *HealthController) Ready(ctx *gin.Context) {
+	if c.Readiness.Draining() {
+		ctx.JSON(http.StatusServiceUnavailable, models.HealthReport{Status: models.HealthStatusDraining})
+		return
+	}
+
+	report := c.Registry.Check(ctx.Request.Context())
+	if report.Status != models.HealthStatusOK {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
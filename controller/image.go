@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for the form boundaries and headers around the
+// uploaded file.
+const multipartOverhead = 64 << 10
+
+type ImageController struct {
+	ImageService *services.ImageService
+	MovieService *services.MovieService
+}
+
+func NewImageController(imageService *services.ImageService, movieService *services.MovieService) *ImageController {
+	return &ImageController{
+		ImageService: imageService,
+		MovieService: movieService,
+	}
+}
+
+// @Summary Upload a movie poster
+// @Security BearerAuth
+// @Description Upload a JPEG, PNG or WebP poster as the multipart field "file", replacing the current one. JPEG thumbnails 185, 342 and 780 pixels wide are generated. Allowed for the owner, moderators and admins.
+// @Accept multipart/form-data
+// @Produce json
+// @Tags Images
+// @Param id path string true "Movie ID"
+// @Param file formData file true "Poster image"
+// @Param If-Match header string false "ETag from a previous GET"
+// @Success 200 {object} models.MovieResponse
+// @Header 200 {string} ETag "New version of the movie"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Router /api/movies/{id}/poster [post]
+func (c *ImageController) UploadPoster(ctx *gin.Context) {
+	c.uploadImage(ctx, models.ImagePoster)
+}
+
+// @Summary Upload a movie backdrop
+// @Security BearerAuth
+// @Description Upload a JPEG, PNG or WebP backdrop as the multipart field "file", replacing the current one. JPEG thumbnails 300, 780 and 1280 pixels wide are generated. Allowed for the owner, moderators and admins.
+// @Accept multipart/form-data
+// @Produce json
+// @Tags Images
+// @Param id path string true "Movie ID"
+// @Param file formData file true "Backdrop image"
+// @Param If-Match header string false "ETag from a previous GET"
+// @Success 200 {object} models.MovieResponse
+// @Header 200 {string} ETag "New version of the movie"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Router /api/movies/{id}/backdrop [post]
+func (c *ImageController) UploadBackdrop(ctx *gin.Context) {
+	c.uploadImage(ctx, models.ImageBackdrop)
+}
+
+// @Summary Delete a movie poster
+// @Security BearerAuth
+// @Description Remove the poster and its thumbnails. Allowed for the owner, moderators and admins.
+// @Produce json
+// @Tags Images
+// @Param id path string true "Movie ID"
+// @Param If-Match header string false "ETag from a previous GET"
+// @Success 200 {object} models.MovieResponse
+// @Header 200 {string} ETag "New version of the movie"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Router /api/movies/{id}/poster [delete]
+func (c *ImageController) DeletePoster(ctx *gin.Context) {
+	c.deleteImage(ctx, models.ImagePoster)
+}
+
+// @Summary Delete a movie backdrop
+// @Security BearerAuth
+// @Description Remove the backdrop and its thumbnails. Allowed for the owner, moderators and admins.
+// @Produce json
+// @Tags Images
+// @Param id path string true "Movie ID"
+// @Param If-Match header string false "ETag from a previous GET"
+// @Success 200 {object} models.MovieResponse
+// @Header 200 {string} ETag "New version of the movie"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Router /api/movies/{id}/backdrop [delete]
+func (c *ImageController) DeleteBackdrop(ctx *gin.Context) {
+	c.deleteImage(ctx, models.ImageBackdrop)
+}
+
+func (c *ImageController) uploadImage(ctx *gin.Context, kind string) {
+	movie, ok := c.loadMovie(ctx)
+	if !ok {
+		return
+	}
+
+	maxBytes := c.ImageService.MaxBytes
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes+multipartOverhead)
+
+	header, err := ctx.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) || (err == nil && header.Size > maxBytes) {
+		respondImageError(ctx, services.ErrImageTooLarge)
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "An image is required in the \"file\" form field"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := c.ImageService.SaveImage(ctx.Request.Context(), &movie, kind, data, middleware.GetActor(ctx)); err != nil {
+		respondImageError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", movieETag(movie))
+	ctx.JSON(http.StatusOK, toMovieResponse(movie))
+}
+
+func (c *ImageController) deleteImage(ctx *gin.Context, kind string) {
+	movie, ok := c.loadMovie(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.ImageService.DeleteImage(&movie, kind, middleware.GetActor(ctx)); err != nil {
+		respondImageError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", movieETag(movie))
+	ctx.JSON(http.StatusOK, toMovieResponse(movie))
+}
+
+// loadMovie reads the movie named by the id parameter and checks If-Match,
+// which is optional for images but must be current when given.
+func (c *ImageController) loadMovie(ctx *gin.Context) (models.Movie, bool) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return models.Movie{}, false
+	}
+
+	movie, err := c.MovieService.GetMovieByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+		return models.Movie{}, false
+	}
+	if ctx.GetHeader("If-Match") != "" && !checkIfMatch(ctx, movieETag(movie)) {
+		return models.Movie{}, false
+	}
+	return movie, true
+}
+
+func respondImageError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMovieNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+	case errors.Is(err, services.ErrNoImage):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to update this movie"})
+	case errors.Is(err, services.ErrVersionConflict):
+		respondPreconditionFailed(ctx)
+	case errors.Is(err, services.ErrImageTooLarge):
+		ctx.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrUnsupportedImage):
+		ctx.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+// toImageResponse returns nil when no image has been uploaded.
+func toImageResponse(set models.ImageSet) *models.ImageResponse {
+	if set.Empty() {
+		return nil
+	}
+
+	thumbnails := make(map[string]string, len(set.URLs))
+	for size, url := range set.URLs {
+		if size != models.ImageOriginal {
+			thumbnails[size] = url
+		}
+	}
+
+	return &models.ImageResponse{
+		URL:        set.URLs[models.ImageOriginal],
+		Width:      set.Width,
+		Height:     set.Height,
+		Thumbnails: thumbnails,
+	}
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	mimeCSV    = "text/csv"
+	mimeNDJSON = "application/x-ndjson"
+	mimeJSONL  = "application/jsonl"
+
+	maxImportBytes = 10 << 20
+)
+
+// importFields are the MovieRequest fields a CSV column can be mapped to.
+var importFields = map[string]bool{
+	"title":    true,
+	"director": true,
+	"year":     true,
+	"plot":     true,
+	"genres":   true,
+}
+
+var errUnsupportedImport = fmt.Errorf("content type must be %s or %s", mimeCSV, mimeNDJSON)
+
+// @Summary Import movies
+// @Security BearerAuth
+// @Description Create movies in bulk from CSV (text/csv) or JSON Lines (application/x-ndjson). CSV columns named after a movie field are used as that field; map other headers with map=header:field. Genres in CSV are comma separated. Each row is validated like POST /api/movies and rows are inserted in batches; failing rows are reported by line number and skipped. With dry_run=true nothing is saved.
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Tags Movies
+// @Param dry_run query bool false "Validate and report without saving"
+// @Param map query []string false "CSV header-to-field mapping, e.g. Name:title" collectionFormat(multi)
+// @Param file body string true "CSV or JSON Lines document"
+// @Success 200 {object} models.ImportResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Router /api/movies/import [post]
+func (c *MovieController) ImportMovies(ctx *gin.Context) {
+	var query models.ImportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBytes)
+
+	var records []services.ImportRecord
+	var err error
+	switch ctx.ContentType() {
+	case mimeCSV:
+		var mapping map[string]string
+		if mapping, err = parseImportMapping(query.Map); err == nil {
+			records, err = decodeCSVImport(body, mapping)
+		}
+	case mimeNDJSON, mimeJSONL:
+		records, err = decodeNDJSONImport(body)
+	default:
+		err = errUnsupportedImport
+	}
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		ctx.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{Error: fmt.Sprintf("import file must not exceed %d bytes", maxImportBytes)})
+		return
+	case errors.Is(err, errUnsupportedImport):
+		ctx.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{Error: err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	imported, failures, err := c.MovieService.ImportRequests(records, middleware.GetUserID(ctx), query.DryRun)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ImportResult{
+		DryRun:   query.DryRun,
+		Total:    len(records),
+		Imported: imported,
+		Failed:   len(failures),
+		Errors:   failures,
+	})
+}
+
+// parseImportMapping reads "header:field" pairs into a header-to-field map.
+// The last colon separates the two, so headers may contain colons.
+func parseImportMapping(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		i := strings.LastIndex(pair, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid mapping %q, expected header:field", pair)
+		}
+
+		header := strings.TrimSpace(pair[:i])
+		field := strings.ToLower(strings.TrimSpace(pair[i+1:]))
+		if !importFields[field] {
+			return nil, fmt.Errorf("invalid mapping %q, unknown field %q", pair, field)
+		}
+		mapping[header] = field
+	}
+	return mapping, nil
+}
+
+// decodeCSVImport reads movies from a CSV document whose first row is a
+// header. Columns that are neither mapped nor named after a field are
+// ignored.
+func decodeCSVImport(r io.Reader, mapping map[string]string) ([]services.ImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if field, ok := mapping[name]; ok {
+			columns[i] = field
+		} else if field := strings.ToLower(name); importFields[field] {
+			columns[i] = field
+		}
+	}
+
+	var records []services.ImportRecord
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			records = append(records, services.ImportRecord{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := services.ImportRecord{Line: line}
+		for i, value := range values {
+			if record.Err = setImportField(&record.Request, columns[i], value); record.Err != nil {
+				break
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+// setImportField stores a CSV value in the named MovieRequest field.
+func setImportField(request *models.MovieRequest, field, value string) error {
+	value = strings.TrimSpace(value)
+	switch field {
+	case "title":
+		request.Title = value
+	case "director":
+		request.Director = value
+	case "plot":
+		request.Plot = value
+	case "year":
+		if value == "" {
+			return nil
+		}
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid year %q", value)
+		}
+		request.Year = year
+	case "genres":
+		for _, genre := range strings.Split(value, ",") {
+			if genre = strings.TrimSpace(genre); genre != "" {
+				request.Genres = append(request.Genres, genre)
+			}
+		}
+	}
+	return nil
+}
+
+// decodeNDJSONImport reads one movie per line from a JSON Lines document,
+// skipping blank lines.
+func decodeNDJSONImport(r io.Reader) ([]services.ImportRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportBytes)
+
+	var records []services.ImportRecord
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		record := services.ImportRecord{Line: line}
+		if err := json.Unmarshal([]byte(text), &record.Request); err != nil {
+			record.Err = fmt.Errorf("invalid JSON: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+)
+
+func TestParseImportMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "none", pairs: nil, want: map[string]string{}},
+		{name: "single", pairs: []string{"Name:title"}, want: map[string]string{"Name": "title"}},
+		{name: "trims and lowercases field", pairs: []string{" Made by : Director "}, want: map[string]string{"Made by": "director"}},
+		{name: "header with colon", pairs: []string{"Time: Released:year"}, want: map[string]string{"Time: Released": "year"}},
+		{name: "several", pairs: []string{"Name:title", "Tags:genres"}, want: map[string]string{"Name": "title", "Tags": "genres"}},
+		{name: "missing colon", pairs: []string{"Name"}, wantErr: true},
+		{name: "unknown field", pairs: []string{"Name:name"}, wantErr: true},
+		{name: "read-only field", pairs: []string{"Score:rating"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseImportMapping(tt.pairs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseImportMapping(%q) = %v, want an error", tt.pairs, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseImportMapping(%q) error = %v", tt.pairs, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseImportMapping(%q) = %v, want %v", tt.pairs, got, tt.want)
+			}
+		})
+	}
+}
+
+// testRecord is a services.ImportRecord with its error reduced to the message, so
+// that decoded records can be compared.
+type testRecord struct {
+	Line    int
+	Request models.MovieRequest
+	Err     string
+}
+
+func testRecords(records []services.ImportRecord) []testRecord {
+	var result []testRecord
+	for _, record := range records {
+		r := testRecord{Line: record.Line, Request: record.Request}
+		if record.Err != nil {
+			r.Err = record.Err.Error()
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func TestDecodeCSVImport(t *testing.T) {
+	heat := models.MovieRequest{Title: "Heat", Director: "Michael Mann", Year: 1995}
+
+	tests := []struct {
+		name    string
+		csv     string
+		mapping map[string]string
+		want    []testRecord
+		wantErr bool
+	}{
+		{
+			name: "named columns",
+			csv:  "title,director,year\nHeat,Michael Mann,1995\n",
+			want: []testRecord{{Line: 2, Request: heat}},
+		},
+		{
+			name: "headers are case-insensitive and may start with a BOM",
+			csv:  "\ufeffTitle, Director ,YEAR\nHeat,Michael Mann,1995\n",
+			want: []testRecord{{Line: 2, Request: heat}},
+		},
+		{
+			name:    "mapped headers",
+			csv:     "Name,Made by,Released\nHeat,Michael Mann,1995\n",
+			mapping: map[string]string{"Name": "title", "Made by": "director", "Released": "year"},
+			want:    []testRecord{{Line: 2, Request: heat}},
+		},
+		{
+			name: "unknown and read-only columns are ignored",
+			csv:  "title,director,year,notes,rating\nHeat,Michael Mann,1995,rewatch,8.3\n",
+			want: []testRecord{{Line: 2, Request: heat}},
+		},
+		{
+			name: "genres are comma separated",
+			csv:  "title,genres\nHeat,\"Crime, Drama,,\"\n",
+			want: []testRecord{{Line: 2, Request: models.MovieRequest{Title: "Heat", Genres: []string{"Crime", "Drama"}}}},
+		},
+		{
+			name: "empty year is left unset",
+			csv:  "title,year\nHeat,\n",
+			want: []testRecord{{Line: 2, Request: models.MovieRequest{Title: "Heat"}}},
+		},
+		{
+			name: "invalid year",
+			csv:  "title,year\nHeat,soon\n",
+			want: []testRecord{{Line: 2, Request: models.MovieRequest{Title: "Heat"}, Err: `invalid year "soon"`}},
+		},
+		{
+			name: "wrong field count is reported per row",
+			csv:  "title,director,year\nHeat,Michael Mann\nHeat,Michael Mann,1995\n",
+			want: []testRecord{
+				{Line: 2, Err: "wrong number of fields"},
+				{Line: 3, Request: heat},
+			},
+		},
+		{
+			name: "line numbers account for multi-line values",
+			csv:  "title,plot\nHeat,\"A heist\ngoes wrong.\"\nRonin,\n",
+			want: []testRecord{
+				{Line: 2, Request: models.MovieRequest{Title: "Heat", Plot: "A heist\ngoes wrong."}},
+				{Line: 4, Request: models.MovieRequest{Title: "Ronin"}},
+			},
+		},
+		{
+			name: "header only",
+			csv:  "title,director,year\n",
+			want: nil,
+		},
+		{
+			name:    "empty file",
+			csv:     "",
+			wantErr: true,
+		},
+		{
+			name:    "malformed quoting",
+			csv:     "title,plot\nHeat,\"unterminated\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := decodeCSVImport(strings.NewReader(tt.csv), tt.mapping)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodeCSVImport() = %+v, want an error", testRecords(records))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeCSVImport() error = %v", err)
+			}
+			if got := testRecords(records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeCSVImport() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeNDJSONImport(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []testRecord
+	}{
+		{
+			name:  "one movie per line",
+			input: "{\"title\":\"Heat\",\"year\":1995}\n{\"title\":\"Ronin\",\"genres\":[\"Action\"]}\n",
+			want: []testRecord{
+				{Line: 1, Request: models.MovieRequest{Title: "Heat", Year: 1995}},
+				{Line: 2, Request: models.MovieRequest{Title: "Ronin", Genres: []string{"Action"}}},
+			},
+		},
+		{
+			name:  "blank lines are skipped but counted",
+			input: "\n{\"title\":\"Heat\"}\n   \n{\"title\":\"Ronin\"}",
+			want: []testRecord{
+				{Line: 2, Request: models.MovieRequest{Title: "Heat"}},
+				{Line: 4, Request: models.MovieRequest{Title: "Ronin"}},
+			},
+		},
+		{
+			name:  "unknown fields are ignored",
+			input: `{"title":"Heat","rating":8.3}`,
+			want:  []testRecord{{Line: 1, Request: models.MovieRequest{Title: "Heat"}}},
+		},
+		{
+			name:  "invalid JSON is reported per line",
+			input: "{\"title\":\n{\"title\":\"Ronin\"}\n",
+			want: []testRecord{
+				{Line: 1, Err: "invalid JSON: unexpected end of JSON input"},
+				{Line: 2, Request: models.MovieRequest{Title: "Ronin"}},
+			},
+		},
+		{
+			name:  "wrong type",
+			input: `{"title":"Heat","year":"1995"}`,
+			want: []testRecord{{
+				Line:    1,
+				Request: models.MovieRequest{Title: "Heat"},
+				Err:     "invalid JSON: json: cannot unmarshal string into Go struct field MovieRequest.year of type int",
+			}},
+		},
+		{
+			name:  "empty",
+			input: "",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := decodeNDJSONImport(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("decodeNDJSONImport() error = %v", err)
+			}
+			if got := testRecords(records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeNDJSONImport() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,549 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ListController struct {
+	ListService *services.ListService
+}
+
+func NewListController(listService *services.ListService) *ListController {
+	return &ListController{
+		ListService: listService,
+	}
+}
+
+// @Summary List public lists
+// @Security BearerAuth
+// @Description Get a page of public lists, most recently updated first
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.Lists
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/lists [get]
+func (c *ListController) GetPublicLists(ctx *gin.Context) {
+	var page models.PageQuery
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	lists, total, err := c.ListService.ListPublicLists(&page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.Lists{
+		Lists:      c.toListResponses(lists, middleware.GetActor(ctx)),
+		Pagination: newPagination(page, total),
+	})
+}
+
+// @Summary List my lists
+// @Security BearerAuth
+// @Description Get a page of the lists the authenticated user owns or collaborates on
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.Lists
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/me/lists [get]
+func (c *ListController) GetMyLists(ctx *gin.Context) {
+	var page models.PageQuery
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	actor := middleware.GetActor(ctx)
+	lists, total, err := c.ListService.ListUserLists(actor.UserID, &page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.Lists{
+		Lists:      c.toListResponses(lists, actor),
+		Pagination: newPagination(page, total),
+	})
+}
+
+// @Summary Create a list
+// @Security BearerAuth
+// @Description Create a list owned by the authenticated user. Visibility defaults to private.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param list body models.ListRequest true "List details"
+// @Success 201 {object} models.ListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/lists [post]
+func (c *ListController) CreateList(ctx *gin.Context) {
+	var request models.ListRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	actor := middleware.GetActor(ctx)
+	list := models.List{
+		UserID:      actor.UserID,
+		Title:       request.Title,
+		Description: request.Description,
+		Visibility:  request.Visibility,
+	}
+
+	if err := c.ListService.CreateList(&list); err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	created, err := c.ListService.GetList(list.ID, actor, "")
+	if err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, c.toListResponse(created, actor))
+}
+
+// @Summary Get a list
+// @Security BearerAuth
+// @Description Get a list. Unlisted lists can be opened by anyone with the share token.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Param token query string false "Share token"
+// @Success 200 {object} models.ListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/lists/{id} [get]
+func (c *ListController) GetList(ctx *gin.Context) {
+	var query models.ListViewQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	list, ok := c.loadList(ctx, query.Token)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, c.toListResponse(list, middleware.GetActor(ctx)))
+}
+
+// @Summary Get the items of a list
+// @Security BearerAuth
+// @Description Get a page of the movies on a list in order
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Param token query string false "Share token"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.ListItems
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/lists/{id}/items [get]
+func (c *ListController) GetListItems(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var query models.ListItemsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	items, total, err := c.ListService.ListItems(id, middleware.GetActor(ctx), query.Token, &query.PageQuery)
+	if err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	responses := make([]models.ListItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = toListItemResponse(item)
+	}
+
+	ctx.JSON(http.StatusOK, models.ListItems{
+		Items:      responses,
+		Pagination: newPagination(query.PageQuery, total),
+	})
+}
+
+// @Summary Update a list
+// @Security BearerAuth
+// @Description Update the title, description and visibility of a list. Allowed for the owner, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Param list body models.ListRequest true "List details"
+// @Success 200 {object} models.ListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/lists/{id} [put]
+func (c *ListController) UpdateList(ctx *gin.Context) {
+	list, ok := c.loadList(ctx, "")
+	if !ok {
+		return
+	}
+
+	var request models.ListRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	list.Title = request.Title
+	list.Description = request.Description
+	if request.Visibility != "" {
+		list.Visibility = request.Visibility
+	}
+
+	actor := middleware.GetActor(ctx)
+	if err := c.ListService.UpdateList(&list, actor); err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, c.toListResponse(list, actor))
+}
+
+// @Summary Delete a list
+// @Security BearerAuth
+// @Description Delete a list with its items and collaborators. Allowed for the owner, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/lists/{id} [delete]
+func (c *ListController) DeleteList(ctx *gin.Context) {
+	list, ok := c.loadList(ctx, "")
+	if !ok {
+		return
+	}
+
+	if err := c.ListService.DeleteList(list, middleware.GetActor(ctx)); err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "List deleted successfully"})
+}
+
+// @Summary Rotate the share token of a list
+// @Security BearerAuth
+// @Description Issue a new share token so that previously shared links stop working. Allowed for the owner, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Success 200 {object} models.ListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/lists/{id}/share-token [post]
+func (c *ListController) RotateShareToken(ctx *gin.Context) {
+	list, ok := c.loadList(ctx, "")
+	if !ok {
+		return
+	}
+
+	actor := middleware.GetActor(ctx)
+	if err := c.ListService.RotateShareToken(&list, actor); err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, c.toListResponse(list, actor))
+}
+
+// @Summary Add a movie to a list
+// @Security BearerAuth
+// @Description Add a movie with an optional note at the given position, or at the end. Allowed for the owner, editors, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Param item body models.ListItemRequest true "Movie, note and optional position"
+// @Success 201 {object} models.ListItemResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/lists/{id}/items [post]
+func (c *ListController) AddListItem(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var request models.ListItemRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	item := models.ListItem{
+		MovieID:  request.MovieID,
+		Note:     request.Note,
+		Position: request.Position,
+	}
+
+	if err := c.ListService.AddItem(id, &item, middleware.GetActor(ctx)); err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toListItemResponse(item))
+}
+
+// @Summary Update a list item
+// @Security BearerAuth
+// @Description Replace the note of a movie on a list and optionally move it. Allowed for the owner, editors, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Param movieId path string true "Movie ID"
+// @Param item body models.ListItemUpdateRequest true "Note and optional new position"
+// @Success 200 {object} models.ListItemResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/lists/{id}/items/{movieId} [put]
+func (c *ListController) UpdateListItem(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	movieID, ok := parseIDParam(ctx, "movieId")
+	if !ok {
+		return
+	}
+
+	var request models.ListItemUpdateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	item, err := c.ListService.UpdateItem(id, movieID, request.Note, request.Position, middleware.GetActor(ctx))
+	if err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toListItemResponse(item))
+}
+
+// @Summary Remove a movie from a list
+// @Security BearerAuth
+// @Description Remove a movie from a list. Allowed for the owner, editors, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Param movieId path string true "Movie ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/lists/{id}/items/{movieId} [delete]
+func (c *ListController) RemoveListItem(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	movieID, ok := parseIDParam(ctx, "movieId")
+	if !ok {
+		return
+	}
+
+	if err := c.ListService.RemoveItem(id, movieID, middleware.GetActor(ctx)); err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Movie removed from list"})
+}
+
+// @Summary Add or change a collaborator
+// @Security BearerAuth
+// @Description Give a user editor or viewer rights on a list. Allowed for the owner, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Param userId path string true "User ID"
+// @Param collaborator body models.CollaboratorRequest true "Role"
+// @Success 200 {object} models.ListCollaboratorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/lists/{id}/collaborators/{userId} [put]
+func (c *ListController) SaveCollaborator(ctx *gin.Context) {
+	list, ok := c.loadList(ctx, "")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(ctx, "userId")
+	if !ok {
+		return
+	}
+
+	var request models.CollaboratorRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	collaborator := models.ListCollaborator{UserID: userID, Role: request.Role}
+	if err := c.ListService.SaveCollaborator(list, &collaborator, middleware.GetActor(ctx)); err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toListCollaboratorResponse(collaborator))
+}
+
+// @Summary Remove a collaborator
+// @Security BearerAuth
+// @Description Revoke a user's rights on a list. Allowed for the owner, moderators, admins and the collaborator themselves.
+// @Accept json
+// @Produce json
+// @Tags Lists
+// @Param id path string true "List ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/lists/{id}/collaborators/{userId} [delete]
+func (c *ListController) RemoveCollaborator(ctx *gin.Context) {
+	list, ok := c.loadList(ctx, "")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(ctx, "userId")
+	if !ok {
+		return
+	}
+
+	if err := c.ListService.RemoveCollaborator(list, userID, middleware.GetActor(ctx)); err != nil {
+		respondListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Collaborator removed successfully"})
+}
+
+func (c *ListController) loadList(ctx *gin.Context, shareToken string) (models.List, bool) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return models.List{}, false
+	}
+
+	list, err := c.ListService.GetList(id, middleware.GetActor(ctx), shareToken)
+	if err != nil {
+		respondListError(ctx, err)
+		return models.List{}, false
+	}
+	return list, true
+}
+
+func respondListError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrListNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "List not found"})
+	case errors.Is(err, services.ErrMovieNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+	case errors.Is(err, services.ErrUserNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "User not found"})
+	case errors.Is(err, services.ErrNotOnList), errors.Is(err, services.ErrCollaboratorNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrListItemExists):
+		ctx.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrOwnerAsCollaborator):
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to change this list"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+// toListResponse includes the share token and collaborators only for actors
+// who may manage the list.
+func (c *ListController) toListResponse(list models.List, actor models.Actor) models.ListResponse {
+	response := models.ListResponse{
+		ID:            list.ID,
+		Title:         list.Title,
+		Description:   list.Description,
+		Visibility:    list.Visibility,
+		OwnerID:       list.UserID,
+		OwnerUsername: list.User.Username,
+		ItemCount:     list.ItemCount,
+		CreatedAt:     list.CreatedAt,
+		UpdatedAt:     list.UpdatedAt,
+	}
+
+	if c.ListService.CanManage(list, actor) {
+		response.ShareToken = list.ShareToken
+		for _, collaborator := range list.Collaborators {
+			response.Collaborators = append(response.Collaborators, toListCollaboratorResponse(collaborator))
+		}
+	}
+	return response
+}
+
+func (c *ListController) toListResponses(lists []models.List, actor models.Actor) []models.ListResponse {
+	responses := make([]models.ListResponse, len(lists))
+	for i, list := range lists {
+		responses[i] = c.toListResponse(list, actor)
+	}
+	return responses
+}
+
+func toListItemResponse(item models.ListItem) models.ListItemResponse {
+	return models.ListItemResponse{
+		Position:  item.Position,
+		Note:      item.Note,
+		AddedByID: item.AddedByID,
+		AddedAt:   item.CreatedAt,
+		Movie:     toMovieResponse(item.Movie),
+	}
+}
+
+func toListCollaboratorResponse(collaborator models.ListCollaborator) models.ListCollaboratorResponse {
+	return models.ListCollaboratorResponse{
+		UserID:   collaborator.UserID,
+		Username: collaborator.User.Username,
+		Role:     collaborator.Role,
+	}
+}
@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dostonshernazarov/movies-app/middleware"
@@ -12,49 +15,103 @@ import (
 )
 
 type MovieController struct {
-	MovieService *services.MovieService
+	MovieService  *services.MovieService
+	UserService   *services.UserService
+	PersonService *services.PersonService
 }
 
-func NewMovieController(movieService *services.MovieService) *MovieController {
+func NewMovieController(movieService *services.MovieService, userService *services.UserService, personService *services.PersonService) *MovieController {
 	return &MovieController{
-		MovieService: movieService,
+		MovieService:  movieService,
+		UserService:   userService,
+		PersonService: personService,
 	}
 }
 
 // @Summary Get all movies
 // @Security BearerAuth
-// @Description Get all movies from the database
+// @Description Get a page of movies, optionally filtered and sorted
 // @Accept json
 // @Produce json
 // @Tags Movies
-// @Success 200 {array} models.Movies
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param genre query string false "Genre name or slug"
+// @Param director query string false "Director (partial match)"
+// @Param min_year query int false "Minimum release year"
+// @Param max_year query int false "Maximum release year"
+// @Param min_rating query number false "Minimum average review rating"
+// @Param max_rating query number false "Maximum average review rating"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (e.g. -rating,year)"
+// @Success 200 {object} models.Movies
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /api/movies [get]
 func (c *MovieController) GetAllMovies(ctx *gin.Context) {
-	movies, err := c.MovieService.GetAllMovies()
+	var query models.MovieListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	movies, total, err := c.MovieService.ListMovies(&query)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidSort) || errors.Is(err, services.ErrInvalidGenre) {
+			ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	movieResponses := make([]models.MovieResponse, len(movies))
-	for i, movie := range movies {
-		movieResponses[i] = models.MovieResponse{
-			ID:        movie.ID,
-			Title:     movie.Title,
-			Director:  movie.Director,
-			Year:      movie.Year,
-			Plot:      movie.Plot,
-			Genre:     movie.Genre,
-			Rating:    movie.Rating,
-			UserID:    movie.UserID,
-			CreatedAt: movie.CreatedAt,
-			UpdatedAt: movie.UpdatedAt,
+	ctx.JSON(http.StatusOK, models.Movies{
+		Movies:     toMovieResponses(movies),
+		Pagination: newPagination(query.PageQuery, total),
+	})
+}
+
+// @Summary Search movies
+// @Security BearerAuth
+// @Description Full-text search across title, director, genre and plot, ordered by relevance. Use "quotes" for phrases and a trailing * for prefix matches.
+// @Accept json
+// @Produce json
+// @Tags Movies
+// @Param q query string true "Search query"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.MovieSearchResults
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/movies/search [get]
+func (c *MovieController) SearchMovies(ctx *gin.Context) {
+	var query models.MovieSearchQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	hits, total, err := c.MovieService.SearchMovies(&query)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptySearch) {
+			ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	results := make([]models.MovieSearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = models.MovieSearchResult{
+			Movie:   toMovieResponse(hit.Movie),
+			Rank:    hit.Rank,
+			Snippet: hit.Snippet,
 		}
 	}
 
-	ctx.JSON(http.StatusOK, models.Movies{
-		Movies: movieResponses,
+	ctx.JSON(http.StatusOK, models.MovieSearchResults{
+		Results:    results,
+		Pagination: newPagination(query.PageQuery, total),
 	})
 }
 
@@ -65,7 +122,11 @@ func (c *MovieController) GetAllMovies(ctx *gin.Context) {
 // @Produce json
 // @Tags Movies
 // @Param id path string true "Movie ID"
+// @Param include query string false "Set to credits to embed cast and crew"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} models.MovieResponse
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "Current version of the movie"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Router /api/movies/{id} [get]
@@ -77,29 +138,50 @@ func (c *MovieController) GetMovieByID(ctx *gin.Context) {
 		return
 	}
 
+	var query models.MovieDetailQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	movie, err := c.MovieService.GetMovieByID(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, models.MovieResponse{
-		ID:        uint(id),
-		Title:     movie.Title,
-		Director:  movie.Director,
-		Year:      movie.Year,
-		Plot:      movie.Plot,
-		Genre:     movie.Genre,
-		Rating:    movie.Rating,
-		UserID:    movie.UserID,
-		CreatedAt: movie.CreatedAt,
-		UpdatedAt: movie.UpdatedAt,
-	})
+	etag := movieETag(movie)
+	ctx.Header("ETag", etag)
+	if notModified(ctx, etag) {
+		return
+	}
+
+	response := toMovieResponse(movie)
+	if includes(query.Include, "credits") {
+		credits, err := c.PersonService.GetMovieCredits(movie.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		response.Credits = toCreditResponses(credits)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// includes reports whether the comma-separated include list names field.
+func includes(include, field string) bool {
+	for _, part := range strings.Split(include, ",") {
+		if strings.TrimSpace(part) == field {
+			return true
+		}
+	}
+	return false
 }
 
 // @Summary Create a new movie
 // @Security BearerAuth
-// @Description Create a new movie with title, director, year, plot, genre, and rating
+// @Description Create a new movie with title, director, year, plot and genres. Ratings come from reviews.
 // @Accept json
 // @Produce json
 // @Tags Movies
@@ -122,8 +204,7 @@ func (c *MovieController) CreateMovie(ctx *gin.Context) {
 		Director: movieRequest.Director,
 		Year:     movieRequest.Year,
 		Plot:     movieRequest.Plot,
-		Genre:    movieRequest.Genre,
-		Rating:   movieRequest.Rating,
+		Genres:   toGenres(movieRequest.Genres),
 		UserID:   userID,
 	}
 
@@ -138,6 +219,7 @@ func (c *MovieController) CreateMovie(ctx *gin.Context) {
 		Year:      movie.Year,
 		Plot:      movie.Plot,
 		Genre:     movie.Genre,
+		Genres:    toGenreResponses(movie.Genres),
 		Rating:    movie.Rating,
 		CreatedAt: movie.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: movie.UpdatedAt.Format(time.RFC3339),
@@ -146,16 +228,20 @@ func (c *MovieController) CreateMovie(ctx *gin.Context) {
 
 // @Summary Update a movie
 // @Security BearerAuth
-// @Description Update a movie with title, director, year, plot, genre, and rating
+// @Description Update a movie with title, director, year, plot and genres. Allowed for the owner, moderators and admins.
 // @Accept json
 // @Produce json
 // @Tags Movies
 // @Param id path string true "Movie ID"
 // @Param movie body models.MovieRequest true "Movie details"
+// @Param If-Match header string true "ETag from a previous GET"
 // @Success 200 {object} models.MovieResponse
+// @Header 200 {string} ETag "New version of the movie"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 403 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 428 {object} models.ErrorResponse
 // @Router /api/movies/{id} [put]
 func (c *MovieController) UpdateMovie(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -177,13 +263,7 @@ func (c *MovieController) UpdateMovie(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
 		return
 	}
-
-	// Get user ID from JWT token
-	userID := middleware.GetUserID(ctx)
-
-	// Check if user owns the movie
-	if existingMovie.UserID != userID {
-		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to update this movie"})
+	if !checkIfMatch(ctx, movieETag(existingMovie)) {
 		return
 	}
 
@@ -192,39 +272,114 @@ func (c *MovieController) UpdateMovie(ctx *gin.Context) {
 	existingMovie.Director = movieRequest.Director
 	existingMovie.Year = movieRequest.Year
 	existingMovie.Plot = movieRequest.Plot
-	existingMovie.Genre = movieRequest.Genre
-	existingMovie.Rating = movieRequest.Rating
+	existingMovie.Genres = toGenres(movieRequest.Genres)
 
-	if err := c.MovieService.UpdateMovie(&existingMovie); err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	// Owners, moderators and admins may update the movie
+	if err := c.MovieService.UpdateMovie(&existingMovie, middleware.GetActor(ctx)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to update this movie"})
+		case errors.Is(err, services.ErrMovieNotFound):
+			ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+		case errors.Is(err, services.ErrVersionConflict):
+			respondPreconditionFailed(ctx)
+		default:
+			ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
 
-	ctx.JSON(http.StatusOK, models.MovieResponse{
-		ID:        uint(id),
-		Title:     existingMovie.Title,
-		Director:  existingMovie.Director,
-		Year:      existingMovie.Year,
-		Plot:      existingMovie.Plot,
-		Genre:     existingMovie.Genre,
-		Rating:    existingMovie.Rating,
-		UserID:    existingMovie.UserID,
-		CreatedAt: existingMovie.CreatedAt,
-		UpdatedAt: existingMovie.UpdatedAt,
-	})
+	ctx.Header("ETag", movieETag(existingMovie))
+	ctx.JSON(http.StatusOK, toMovieResponse(existingMovie))
+}
+
+// @Summary Partially update a movie
+// @Security BearerAuth
+// @Description Apply a JSON Merge Patch (application/merge-patch+json) or JSON Patch (application/json-patch+json) to the editable fields of a movie (title, director, year, plot, genres). The result is validated like a PUT body and only changed fields are saved. Allowed for the owner, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Movies
+// @Param id path string true "Movie ID"
+// @Param patch body object true "Merge patch or JSON Patch document"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} models.MovieResponse
+// @Header 200 {string} ETag "New version of the movie"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 428 {object} models.ErrorResponse
+// @Router /api/movies/{id} [patch]
+func (c *MovieController) PatchMovie(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	movie, err := c.MovieService.GetMovieByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+		return
+	}
+	if !checkIfMatch(ctx, movieETag(movie)) {
+		return
+	}
+
+	movieRequest := toMovieRequest(movie)
+	if err := applyPatch(ctx.ContentType(), patch, &movieRequest); err != nil {
+		if !respondPatchError(ctx, err) {
+			ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	movie.Title = movieRequest.Title
+	movie.Director = movieRequest.Director
+	movie.Year = movieRequest.Year
+	movie.Plot = movieRequest.Plot
+	movie.Genres = toGenres(movieRequest.Genres)
+
+	if err := c.MovieService.PatchMovie(&movie, middleware.GetActor(ctx)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to update this movie"})
+		case errors.Is(err, services.ErrMovieNotFound):
+			ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+		case errors.Is(err, services.ErrVersionConflict):
+			respondPreconditionFailed(ctx)
+		default:
+			ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	ctx.Header("ETag", movieETag(movie))
+	ctx.JSON(http.StatusOK, toMovieResponse(movie))
 }
 
 // @Summary Delete a movie
 // @Security BearerAuth
-// @Description Delete a movie by ID from the database
+// @Description Delete a movie by ID from the database. Allowed for the owner, moderators and admins.
 // @Accept json
 // @Produce json
 // @Tags Movies
 // @Param id path string true "Movie ID"
+// @Param If-Match header string true "ETag from a previous GET"
 // @Success 200 {object} string
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 403 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 428 {object} models.ErrorResponse
 // @Router /api/movies/{id} [delete]
 func (c *MovieController) DeleteMovie(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -241,19 +396,161 @@ func (c *MovieController) DeleteMovie(ctx *gin.Context) {
 		return
 	}
 
-	// Get user ID from JWT token
-	userID := middleware.GetUserID(ctx)
+	if !checkIfMatch(ctx, movieETag(existingMovie)) {
+		return
+	}
+
+	// Owners, moderators and admins may delete the movie
+	if err := c.MovieService.DeleteMovie(&existingMovie, middleware.GetActor(ctx)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to delete this movie"})
+		case errors.Is(err, services.ErrMovieNotFound):
+			ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+		case errors.Is(err, services.ErrVersionConflict):
+			respondPreconditionFailed(ctx)
+		default:
+			ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Movie deleted successfully"})
+}
+
+// @Summary Get my movies
+// @Security BearerAuth
+// @Description Get a page of the movies created by the authenticated user, with per-user stats
+// @Accept json
+// @Produce json
+// @Tags Movies
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param genre query string false "Genre name or slug"
+// @Param director query string false "Director (partial match)"
+// @Param min_year query int false "Minimum release year"
+// @Param max_year query int false "Maximum release year"
+// @Param min_rating query number false "Minimum average review rating"
+// @Param max_rating query number false "Maximum average review rating"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (e.g. -rating,year)"
+// @Success 200 {object} models.UserMovies
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/me/movies [get]
+func (c *MovieController) GetMyMovies(ctx *gin.Context) {
+	user, err := c.UserService.GetUserByID(middleware.GetUserID(ctx))
+	if err != nil {
+		respondUserError(ctx, err)
+		return
+	}
+
+	c.respondUserMovies(ctx, user)
+}
 
-	// Check if user owns the movie
-	if existingMovie.UserID != userID {
-		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to delete this movie"})
+// @Summary Get a user's movies
+// @Description Get a page of the movies created by a user, with per-user stats
+// @Accept json
+// @Produce json
+// @Tags Movies
+// @Param username path string true "Username"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param genre query string false "Genre name or slug"
+// @Param director query string false "Director (partial match)"
+// @Param min_year query int false "Minimum release year"
+// @Param max_year query int false "Maximum release year"
+// @Param min_rating query number false "Minimum average review rating"
+// @Param max_rating query number false "Maximum average review rating"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (e.g. -rating,year)"
+// @Success 200 {object} models.UserMovies
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/users/{username}/movies [get]
+func (c *MovieController) GetUserMovies(ctx *gin.Context) {
+	user, err := c.UserService.GetUserByUsername(ctx.Param("username"))
+	if err != nil {
+		respondUserError(ctx, err)
 		return
 	}
 
-	if err := c.MovieService.DeleteMovie(uint(id)); err != nil {
+	c.respondUserMovies(ctx, user)
+}
+
+func (c *MovieController) respondUserMovies(ctx *gin.Context, user models.User) {
+	var query models.MovieListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	movies, total, err := c.MovieService.GetUserMovies(user.ID, &query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSort) || errors.Is(err, services.ErrInvalidGenre) {
+			ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Movie deleted successfully"})
+	stats, err := c.MovieService.GetUserStats(user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.UserMovies{
+		Username:   user.Username,
+		Stats:      stats,
+		Movies:     toMovieResponses(movies),
+		Pagination: newPagination(query.PageQuery, total),
+	})
+}
+
+// toMovieRequest returns the editable fields of a movie, the document that
+// PATCH requests apply to.
+func toMovieRequest(movie models.Movie) models.MovieRequest {
+	genres := make([]string, len(movie.Genres))
+	for i, genre := range movie.Genres {
+		genres[i] = genre.Name
+	}
+
+	return models.MovieRequest{
+		Title:    movie.Title,
+		Director: movie.Director,
+		Year:     movie.Year,
+		Plot:     movie.Plot,
+		Genres:   genres,
+	}
+}
+
+func toMovieResponse(movie models.Movie) models.MovieResponse {
+	return models.MovieResponse{
+		ID:            movie.ID,
+		Title:         movie.Title,
+		Director:      movie.Director,
+		Year:          movie.Year,
+		Plot:          movie.Plot,
+		Genre:         movie.Genre,
+		Genres:        toGenreResponses(movie.Genres),
+		Rating:        movie.Rating,
+		AverageRating: movie.AverageRating,
+		VoteCount:     movie.VoteCount,
+		Version:       movie.Version,
+		Poster:        toImageResponse(movie.Poster),
+		Backdrop:      toImageResponse(movie.Backdrop),
+		UserID:        movie.UserID,
+		CreatedAt:     movie.CreatedAt,
+		UpdatedAt:     movie.UpdatedAt,
+	}
+}
+
+func toMovieResponses(movies []models.Movie) []models.MovieResponse {
+	responses := make([]models.MovieResponse, len(movies))
+	for i, movie := range movies {
+		responses[i] = toMovieResponse(movie)
+	}
+	return responses
 }
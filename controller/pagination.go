@@ -0,0 +1,28 @@
+package controllers
+
+import "github.com/dostonshernazarov/movies-app/models"
+
+func newPagination(page models.PageQuery, total int64) models.Pagination {
+	totalPages := int((total + int64(page.Limit) - 1) / int64(page.Limit))
+
+	pagination := models.Pagination{
+		Page:       page.Page,
+		Limit:      page.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	if page.Page < totalPages {
+		next := page.Page + 1
+		pagination.NextPage = &next
+	}
+	if page.Page > 1 {
+		prev := page.Page - 1
+		if prev > totalPages && totalPages > 0 {
+			prev = totalPages
+		}
+		pagination.PrevPage = &prev
+	}
+
+	return pagination
+}
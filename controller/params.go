@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/gin-gonic/gin"
+)
+
+// parseIDParam reads a numeric path parameter. On failure it writes a 400
+// response and returns false.
+func parseIDParam(ctx *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
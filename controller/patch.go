@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+var (
+	errUnsupportedPatch = fmt.Errorf("content type must be %s or %s", mimeMergePatch, mimeJSONPatch)
+	errInvalidPatch     = errors.New("invalid patch document")
+	errPatchConflict    = errors.New("patch test operation failed")
+	errPatchedInvalid   = errors.New("patched document is invalid")
+)
+
+// applyPatch applies a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902)
+// document, chosen by content type, to the JSON form of target. The result
+// is decoded back into target, rejecting unknown fields, and validated with
+// its binding tags.
+func applyPatch[T any](contentType string, patch []byte, target *T) error {
+	doc, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	switch contentType {
+	case mimeMergePatch:
+		doc, err = jsonpatch.MergePatch(doc, patch)
+	case mimeJSONPatch:
+		var operations jsonpatch.Patch
+		if operations, err = jsonpatch.DecodePatch(patch); err == nil {
+			doc, err = operations.Apply(doc)
+		}
+	default:
+		return errUnsupportedPatch
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return fmt.Errorf("%w: %v", errPatchConflict, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidPatch, err)
+	}
+
+	// Decode into a zero value so that removed fields are cleared.
+	var patched T
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return fmt.Errorf("%w: %v", errPatchedInvalid, err)
+	}
+	if err := binding.Validator.ValidateStruct(&patched); err != nil {
+		return fmt.Errorf("%w: %v", errPatchedInvalid, err)
+	}
+
+	*target = patched
+	return nil
+}
+
+// respondPatchError writes the status for an applyPatch error and reports
+// whether err was one.
+func respondPatchError(ctx *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, errUnsupportedPatch):
+		ctx.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, errInvalidPatch):
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, errPatchConflict):
+		ctx.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, errPatchedInvalid):
+		ctx.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{Error: err.Error()})
+	default:
+		return false
+	}
+	return true
+}
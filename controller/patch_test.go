@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dostonshernazarov/movies-app/models"
+)
+
+func TestApplyPatch(t *testing.T) {
+	original := models.MovieRequest{
+		Title:    "Heat",
+		Director: "Michael Mann",
+		Year:     1995,
+		Plot:     "A heist goes wrong.",
+		Genres:   []string{"Crime"},
+	}
+	with := func(change func(*models.MovieRequest)) models.MovieRequest {
+		movie := original
+		movie.Genres = append([]string(nil), original.Genres...)
+		change(&movie)
+		return movie
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		want        models.MovieRequest
+		wantErr     error
+	}{
+		{
+			name:        "merge patch sets a field",
+			contentType: mimeMergePatch,
+			patch:       `{"title": "Heat (1995)"}`,
+			want:        with(func(m *models.MovieRequest) { m.Title = "Heat (1995)" }),
+		},
+		{
+			name:        "merge patch null clears a field",
+			contentType: mimeMergePatch,
+			patch:       `{"plot": null}`,
+			want:        with(func(m *models.MovieRequest) { m.Plot = "" }),
+		},
+		{
+			name:        "merge patch replaces arrays",
+			contentType: mimeMergePatch,
+			patch:       `{"genres": ["Drama", "Thriller"]}`,
+			want:        with(func(m *models.MovieRequest) { m.Genres = []string{"Drama", "Thriller"} }),
+		},
+		{
+			name:        "merge patch empty document",
+			contentType: mimeMergePatch,
+			patch:       `{}`,
+			want:        original,
+		},
+		{
+			name:        "merge patch clearing a required field",
+			contentType: mimeMergePatch,
+			patch:       `{"title": null}`,
+			wantErr:     errPatchedInvalid,
+		},
+		{
+			name:        "merge patch unknown field",
+			contentType: mimeMergePatch,
+			patch:       `{"rating": 8.5}`,
+			wantErr:     errPatchedInvalid,
+		},
+		{
+			name:        "merge patch wrong type",
+			contentType: mimeMergePatch,
+			patch:       `{"year": "1995"}`,
+			wantErr:     errPatchedInvalid,
+		},
+		{
+			name:        "merge patch too many genres",
+			contentType: mimeMergePatch,
+			patch:       `{"genres": ["a", "b", "c", "d", "e", "f"]}`,
+			wantErr:     errPatchedInvalid,
+		},
+		{
+			name:        "merge patch malformed",
+			contentType: mimeMergePatch,
+			patch:       `{"title":`,
+			wantErr:     errInvalidPatch,
+		},
+		{
+			name:        "json patch replace",
+			contentType: mimeJSONPatch,
+			patch:       `[{"op": "replace", "path": "/year", "value": 1996}]`,
+			want:        with(func(m *models.MovieRequest) { m.Year = 1996 }),
+		},
+		{
+			name:        "json patch append to genres",
+			contentType: mimeJSONPatch,
+			patch:       `[{"op": "add", "path": "/genres/-", "value": "Drama"}]`,
+			want:        with(func(m *models.MovieRequest) { m.Genres = []string{"Crime", "Drama"} }),
+		},
+		{
+			name:        "json patch remove genre",
+			contentType: mimeJSONPatch,
+			patch:       `[{"op": "remove", "path": "/genres/0"}]`,
+			want:        with(func(m *models.MovieRequest) { m.Genres = []string{} }),
+		},
+		{
+			name:        "json patch passing test",
+			contentType: mimeJSONPatch,
+			patch:       `[{"op": "test", "path": "/year", "value": 1995}, {"op": "replace", "path": "/title", "value": "Heat 2"}]`,
+			want:        with(func(m *models.MovieRequest) { m.Title = "Heat 2" }),
+		},
+		{
+			name:        "json patch failing test",
+			contentType: mimeJSONPatch,
+			patch:       `[{"op": "test", "path": "/year", "value": 2000}, {"op": "replace", "path": "/title", "value": "Heat 2"}]`,
+			wantErr:     errPatchConflict,
+		},
+		{
+			name:        "json patch missing path",
+			contentType: mimeJSONPatch,
+			patch:       `[{"op": "replace", "path": "/missing/field", "value": 1}]`,
+			wantErr:     errInvalidPatch,
+		},
+		{
+			name:        "json patch not an array",
+			contentType: mimeJSONPatch,
+			patch:       `{"op": "replace", "path": "/year", "value": 1996}`,
+			wantErr:     errInvalidPatch,
+		},
+		{
+			name:        "json patch removing a required field",
+			contentType: mimeJSONPatch,
+			patch:       `[{"op": "remove", "path": "/director"}]`,
+			wantErr:     errPatchedInvalid,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "application/json",
+			patch:       `{"title": "Heat"}`,
+			wantErr:     errUnsupportedPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := with(func(*models.MovieRequest) {})
+			err := applyPatch(tt.contentType, []byte(tt.patch), &target)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("applyPatch() error = %v, want %v", err, tt.wantErr)
+				}
+				if !reflect.DeepEqual(target, original) {
+					t.Errorf("applyPatch() changed the target on error: %+v", target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyPatch() error = %v", err)
+			}
+			if !reflect.DeepEqual(target, tt.want) {
+				t.Errorf("applyPatch() = %+v, want %+v", target, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PersonController struct {
+	PersonService *services.PersonService
+}
+
+func NewPersonController(personService *services.PersonService) *PersonController {
+	return &PersonController{
+		PersonService: personService,
+	}
+}
+
+// @Summary List people
+// @Security BearerAuth
+// @Description Get a page of directors, actors and crew, optionally filtered by name
+// @Accept json
+// @Produce json
+// @Tags People
+// @Param q query string false "Name (partial match)"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.People
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/people [get]
+func (c *PersonController) GetPeople(ctx *gin.Context) {
+	var query models.PersonListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	people, total, err := c.PersonService.ListPeople(&query)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	responses := make([]models.PersonResponse, len(people))
+	for i, person := range people {
+		responses[i] = toPersonResponse(person)
+	}
+
+	ctx.JSON(http.StatusOK, models.People{
+		People:     responses,
+		Pagination: newPagination(query.PageQuery, total),
+	})
+}
+
+// @Summary Get a person
+// @Security BearerAuth
+// @Description Get a person by ID
+// @Accept json
+// @Produce json
+// @Tags People
+// @Param id path string true "Person ID"
+// @Success 200 {object} models.PersonResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/people/{id} [get]
+func (c *PersonController) GetPerson(ctx *gin.Context) {
+	person, ok := c.loadPerson(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toPersonResponse(person))
+}
+
+// @Summary Create a person
+// @Security BearerAuth
+// @Description Add a director, actor or crew member to the catalog
+// @Accept json
+// @Produce json
+// @Tags People
+// @Param person body models.PersonRequest true "Person details"
+// @Success 201 {object} models.PersonResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/people [post]
+func (c *PersonController) CreatePerson(ctx *gin.Context) {
+	var request models.PersonRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	person := models.Person{CreatedByID: middleware.GetUserID(ctx)}
+	applyPersonRequest(&person, request)
+
+	if err := c.PersonService.CreatePerson(&person); err != nil {
+		respondPersonError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toPersonResponse(person))
+}
+
+// @Summary Update a person
+// @Security BearerAuth
+// @Description Update a person. Allowed for the creator, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags People
+// @Param id path string true "Person ID"
+// @Param person body models.PersonRequest true "Person details"
+// @Success 200 {object} models.PersonResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/people/{id} [put]
+func (c *PersonController) UpdatePerson(ctx *gin.Context) {
+	person, ok := c.loadPerson(ctx)
+	if !ok {
+		return
+	}
+
+	var request models.PersonRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	applyPersonRequest(&person, request)
+
+	if err := c.PersonService.UpdatePerson(&person, middleware.GetActor(ctx)); err != nil {
+		respondPersonError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toPersonResponse(person))
+}
+
+// @Summary Delete a person
+// @Security BearerAuth
+// @Description Delete a person and all of their credits. Allowed for the creator, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags People
+// @Param id path string true "Person ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/people/{id} [delete]
+func (c *PersonController) DeletePerson(ctx *gin.Context) {
+	person, ok := c.loadPerson(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.PersonService.DeletePerson(person, middleware.GetActor(ctx)); err != nil {
+		respondPersonError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Person deleted successfully"})
+}
+
+// @Summary Get a person's filmography
+// @Security BearerAuth
+// @Description Get every movie credit of a person, newest first
+// @Accept json
+// @Produce json
+// @Tags People
+// @Param id path string true "Person ID"
+// @Success 200 {object} models.Filmography
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/people/{id}/filmography [get]
+func (c *PersonController) GetFilmography(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	person, credits, err := c.PersonService.GetFilmography(id)
+	if err != nil {
+		respondPersonError(ctx, err)
+		return
+	}
+
+	entries := make([]models.FilmographyEntry, len(credits))
+	for i, credit := range credits {
+		entries[i] = models.FilmographyEntry{
+			CreditID:  credit.ID,
+			MovieID:   credit.MovieID,
+			Title:     credit.Movie.Title,
+			Year:      credit.Movie.Year,
+			Role:      credit.Role,
+			Character: credit.Character,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, models.Filmography{
+		Person:  toPersonResponse(person),
+		Credits: entries,
+	})
+}
+
+// @Summary List credits of a movie
+// @Security BearerAuth
+// @Description Get the cast and crew of a movie in billing order
+// @Accept json
+// @Produce json
+// @Tags People
+// @Param id path string true "Movie ID"
+// @Success 200 {object} models.Credits
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/credits [get]
+func (c *PersonController) GetMovieCredits(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	credits, err := c.PersonService.GetMovieCredits(movieID)
+	if err != nil {
+		respondPersonError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.Credits{Credits: toCreditResponses(credits)})
+}
+
+// @Summary Credit a person on a movie
+// @Security BearerAuth
+// @Description Add a director, actor, writer or composer credit. Allowed for the movie owner, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags People
+// @Param id path string true "Movie ID"
+// @Param credit body models.CreditRequest true "Credit"
+// @Success 201 {object} models.CreditResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/credits [post]
+func (c *PersonController) AddMovieCredit(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var request models.CreditRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	credit := models.Credit{
+		MovieID:   movieID,
+		PersonID:  request.PersonID,
+		Role:      request.Role,
+		Character: request.Character,
+		Position:  request.Position,
+	}
+
+	if err := c.PersonService.AddCredit(&credit, middleware.GetActor(ctx)); err != nil {
+		respondPersonError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toCreditResponse(credit))
+}
+
+// @Summary Remove a credit from a movie
+// @Security BearerAuth
+// @Description Delete a credit. Allowed for the movie owner, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags People
+// @Param id path string true "Movie ID"
+// @Param creditId path string true "Credit ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/credits/{creditId} [delete]
+func (c *PersonController) DeleteMovieCredit(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	creditID, ok := parseIDParam(ctx, "creditId")
+	if !ok {
+		return
+	}
+
+	if err := c.PersonService.DeleteCredit(movieID, creditID, middleware.GetActor(ctx)); err != nil {
+		respondPersonError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Credit deleted successfully"})
+}
+
+func (c *PersonController) loadPerson(ctx *gin.Context) (models.Person, bool) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return models.Person{}, false
+	}
+
+	person, err := c.PersonService.GetPerson(id)
+	if err != nil {
+		respondPersonError(ctx, err)
+		return models.Person{}, false
+	}
+	return person, true
+}
+
+func applyPersonRequest(person *models.Person, request models.PersonRequest) {
+	person.Name = request.Name
+	person.Bio = request.Bio
+	person.BirthDate = nil
+	if request.BirthDate != "" {
+		// Already validated by the datetime binding.
+		birthDate, _ := time.Parse(time.DateOnly, request.BirthDate)
+		person.BirthDate = &birthDate
+	}
+}
+
+func respondPersonError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPersonNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Person not found"})
+	case errors.Is(err, services.ErrMovieNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+	case errors.Is(err, services.ErrCreditNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Credit not found"})
+	case errors.Is(err, services.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to change this record"})
+	case errors.Is(err, services.ErrPersonExists):
+		ctx.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+func toPersonResponse(person models.Person) models.PersonResponse {
+	response := models.PersonResponse{
+		ID:        person.ID,
+		Name:      person.Name,
+		Bio:       person.Bio,
+		CreatedAt: person.CreatedAt,
+		UpdatedAt: person.UpdatedAt,
+	}
+	if person.BirthDate != nil {
+		response.BirthDate = person.BirthDate.Format(time.DateOnly)
+	}
+	return response
+}
+
+func toCreditResponse(credit models.Credit) models.CreditResponse {
+	return models.CreditResponse{
+		ID:         credit.ID,
+		PersonID:   credit.PersonID,
+		PersonName: credit.Person.Name,
+		Role:       credit.Role,
+		Character:  credit.Character,
+		Position:   credit.Position,
+	}
+}
+
+func toCreditResponses(credits []models.Credit) []models.CreditResponse {
+	responses := make([]models.CreditResponse, len(credits))
+	for i, credit := range credits {
+		responses[i] = toCreditResponse(credit)
+	}
+	return responses
+}
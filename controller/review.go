@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewController struct {
+	ReviewService *services.ReviewService
+}
+
+func NewReviewController(reviewService *services.ReviewService) *ReviewController {
+	return &ReviewController{
+		ReviewService: reviewService,
+	}
+}
+
+// @Summary List reviews of a movie
+// @Security BearerAuth
+// @Description Get a page of reviews for a movie, newest first
+// @Accept json
+// @Produce json
+// @Tags Reviews
+// @Param id path string true "Movie ID"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.Reviews
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/reviews [get]
+func (c *ReviewController) GetReviews(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	reviews, total, err := c.ReviewService.ListReviews(movieID, &page)
+	if err != nil {
+		respondReviewError(ctx, err)
+		return
+	}
+
+	responses := make([]models.ReviewResponse, len(reviews))
+	for i, review := range reviews {
+		responses[i] = toReviewResponse(review)
+	}
+
+	ctx.JSON(http.StatusOK, models.Reviews{
+		Reviews:    responses,
+		Pagination: newPagination(page, total),
+	})
+}
+
+// @Summary Get a review
+// @Security BearerAuth
+// @Description Get a single review of a movie
+// @Accept json
+// @Produce json
+// @Tags Reviews
+// @Param id path string true "Movie ID"
+// @Param reviewId path string true "Review ID"
+// @Success 200 {object} models.ReviewResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/reviews/{reviewId} [get]
+func (c *ReviewController) GetReview(ctx *gin.Context) {
+	review, ok := c.loadReview(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toReviewResponse(review))
+}
+
+// @Summary Review a movie
+// @Security BearerAuth
+// @Description Add the authenticated user's 1-10 score and optional text. Each user can review a movie once.
+// @Accept json
+// @Produce json
+// @Tags Reviews
+// @Param id path string true "Movie ID"
+// @Param review body models.ReviewRequest true "Review"
+// @Success 201 {object} models.ReviewResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/movies/{id}/reviews [post]
+func (c *ReviewController) CreateReview(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var request models.ReviewRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	review := models.Review{
+		MovieID: movieID,
+		UserID:  middleware.GetUserID(ctx),
+		Score:   request.Score,
+		Text:    request.Text,
+	}
+
+	if err := c.ReviewService.CreateReview(&review); err != nil {
+		respondReviewError(ctx, err)
+		return
+	}
+
+	review, err := c.ReviewService.GetReview(movieID, review.ID)
+	if err != nil {
+		respondReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toReviewResponse(review))
+}
+
+// @Summary Update a review
+// @Security BearerAuth
+// @Description Change the score or text of a review. Allowed for the author, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Reviews
+// @Param id path string true "Movie ID"
+// @Param reviewId path string true "Review ID"
+// @Param review body models.ReviewRequest true "Review"
+// @Success 200 {object} models.ReviewResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/reviews/{reviewId} [put]
+func (c *ReviewController) UpdateReview(ctx *gin.Context) {
+	review, ok := c.loadReview(ctx)
+	if !ok {
+		return
+	}
+
+	var request models.ReviewRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	review.Score = request.Score
+	review.Text = request.Text
+
+	if err := c.ReviewService.UpdateReview(&review, middleware.GetActor(ctx)); err != nil {
+		respondReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toReviewResponse(review))
+}
+
+// @Summary Delete a review
+// @Security BearerAuth
+// @Description Delete a review. Allowed for the author, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags Reviews
+// @Param id path string true "Movie ID"
+// @Param reviewId path string true "Review ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/reviews/{reviewId} [delete]
+func (c *ReviewController) DeleteReview(ctx *gin.Context) {
+	review, ok := c.loadReview(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.ReviewService.DeleteReview(review, middleware.GetActor(ctx)); err != nil {
+		respondReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Review deleted successfully"})
+}
+
+func (c *ReviewController) loadReview(ctx *gin.Context) (models.Review, bool) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return models.Review{}, false
+	}
+	reviewID, ok := parseIDParam(ctx, "reviewId")
+	if !ok {
+		return models.Review{}, false
+	}
+
+	review, err := c.ReviewService.GetReview(movieID, reviewID)
+	if err != nil {
+		respondReviewError(ctx, err)
+		return models.Review{}, false
+	}
+	return review, true
+}
+
+func respondReviewError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMovieNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+	case errors.Is(err, services.ErrReviewNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Review not found"})
+	case errors.Is(err, services.ErrReviewExists):
+		ctx.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to change this review"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+func toReviewResponse(review models.Review) models.ReviewResponse {
+	return models.ReviewResponse{
+		ID:        review.ID,
+		MovieID:   review.MovieID,
+		UserID:    review.UserID,
+		Username:  review.User.Username,
+		Score:     review.Score,
+		Text:      review.Text,
+		CreatedAt: review.CreatedAt,
+		UpdatedAt: review.UpdatedAt,
+	}
+}
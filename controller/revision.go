@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type RevisionController struct {
+	RevisionService *services.RevisionService
+	MovieService    *services.MovieService
+}
+
+func NewRevisionController(revisionService *services.RevisionService, movieService *services.MovieService) *RevisionController {
+	return &RevisionController{
+		RevisionService: revisionService,
+		MovieService:    movieService,
+	}
+}
+
+// @Summary Get the edit history of a movie
+// @Security BearerAuth
+// @Description Get a page of a movie's revisions, newest first. Each revision holds a full snapshot of the movie.
+// @Accept json
+// @Produce json
+// @Tags History
+// @Param id path string true "Movie ID"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.Revisions
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/history [get]
+func (c *RevisionController) GetHistory(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var page models.PageQuery
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	revisions, total, err := c.RevisionService.ListRevisions(movieID, &page)
+	if err != nil {
+		respondRevisionError(ctx, err)
+		return
+	}
+
+	responses := make([]models.RevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = toRevisionResponse(revision)
+	}
+
+	ctx.JSON(http.StatusOK, models.Revisions{
+		Revisions:  responses,
+		Pagination: newPagination(page, total),
+	})
+}
+
+// @Summary Get a revision of a movie
+// @Security BearerAuth
+// @Description Get a single revision with its snapshot
+// @Accept json
+// @Produce json
+// @Tags History
+// @Param id path string true "Movie ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} models.RevisionResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/history/{rev} [get]
+func (c *RevisionController) GetRevision(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	revision, ok := parseRevisionParam(ctx)
+	if !ok {
+		return
+	}
+
+	rev, err := c.RevisionService.GetRevision(movieID, revision)
+	if err != nil {
+		respondRevisionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toRevisionResponse(rev))
+}
+
+// @Summary Compare two revisions of a movie
+// @Security BearerAuth
+// @Description List the fields that differ between two revisions
+// @Accept json
+// @Produce json
+// @Tags History
+// @Param id path string true "Movie ID"
+// @Param from query int true "Older revision"
+// @Param to query int true "Newer revision"
+// @Success 200 {object} models.RevisionDiff
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/history/diff [get]
+func (c *RevisionController) DiffRevisions(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var query models.RevisionDiffQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	changes, err := c.RevisionService.DiffRevisions(movieID, query.From, query.To)
+	if err != nil {
+		respondRevisionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.RevisionDiff{
+		MovieID: movieID,
+		From:    query.From,
+		To:      query.To,
+		Changes: changes,
+	})
+}
+
+// @Summary Revert a movie to an earlier revision
+// @Security BearerAuth
+// @Description Restore the title, director, year, plot, genres and rating of an earlier revision. The revert is recorded as a new revision. Allowed for the owner, moderators and admins.
+// @Accept json
+// @Produce json
+// @Tags History
+// @Param id path string true "Movie ID"
+// @Param rev path int true "Revision to restore"
+// @Param If-Match header string false "ETag from a previous GET"
+// @Success 200 {object} models.MovieResponse
+// @Header 200 {string} ETag "New version of the movie"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Router /api/movies/{id}/revert/{rev} [post]
+func (c *RevisionController) RevertMovie(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	revision, ok := parseRevisionParam(ctx)
+	if !ok {
+		return
+	}
+
+	movie, err := c.MovieService.GetMovieByID(movieID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+		return
+	}
+	// If-Match is optional here; when given it must be current.
+	if ctx.GetHeader("If-Match") != "" && !checkIfMatch(ctx, movieETag(movie)) {
+		return
+	}
+
+	if err := c.MovieService.RevertMovie(&movie, revision, middleware.GetActor(ctx)); err != nil {
+		respondRevisionError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", movieETag(movie))
+	ctx.JSON(http.StatusOK, toMovieResponse(movie))
+}
+
+func parseRevisionParam(ctx *gin.Context) (int, bool) {
+	revision, err := strconv.Atoi(ctx.Param("rev"))
+	if err != nil || revision < 1 {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid revision"})
+		return 0, false
+	}
+	return revision, true
+}
+
+func respondRevisionError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMovieNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+	case errors.Is(err, services.ErrRevisionNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Revision not found"})
+	case errors.Is(err, services.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to update this movie"})
+	case errors.Is(err, services.ErrVersionConflict):
+		respondPreconditionFailed(ctx)
+	default:
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+func toRevisionResponse(revision models.MovieRevision) models.RevisionResponse {
+	return models.RevisionResponse{
+		Revision:       revision.Revision,
+		Action:         revision.Action,
+		EditorID:       revision.EditorID,
+		EditorUsername: revision.Editor.Username,
+		RevertedFrom:   revision.RevertedFrom,
+		CreatedAt:      revision.CreatedAt,
+		Snapshot:       revision.Snapshot,
+	}
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type TrashController struct {
+	MovieService *services.MovieService
+	TrashPurger  *services.TrashPurger
+}
+
+func NewTrashController(movieService *services.MovieService, trashPurger *services.TrashPurger) *TrashController {
+	return &TrashController{
+		MovieService: movieService,
+		TrashPurger:  trashPurger,
+	}
+}
+
+// @Summary Get my trash
+// @Security BearerAuth
+// @Description Get a page of the authenticated user's deleted movies, most recently deleted first, with the time each will be purged
+// @Accept json
+// @Produce json
+// @Tags Trash
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.Trash
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/me/trash [get]
+func (c *TrashController) GetTrash(ctx *gin.Context) {
+	var page models.PageQuery
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	movies, total, err := c.MovieService.ListTrash(middleware.GetUserID(ctx), &page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	entries := make([]models.TrashEntry, len(movies))
+	for i, movie := range movies {
+		entries[i] = models.TrashEntry{
+			Movie:     toMovieResponse(movie),
+			DeletedAt: movie.DeletedAt.Time,
+		}
+		if c.TrashPurger.Enabled() {
+			purgeAt := c.TrashPurger.PurgeAt(movie.DeletedAt.Time)
+			entries[i].PurgeAt = &purgeAt
+		}
+	}
+
+	ctx.JSON(http.StatusOK, models.Trash{
+		Movies:     entries,
+		Pagination: newPagination(page, total),
+	})
+}
+
+// @Summary Restore a deleted movie
+// @Security BearerAuth
+// @Description Move a movie out of the trash. Owners can restore movies they deleted; movies removed by a moderator or admin can only be restored by one.
+// @Accept json
+// @Produce json
+// @Tags Trash
+// @Param id path string true "Movie ID"
+// @Success 200 {object} models.MovieResponse
+// @Header 200 {string} ETag "New version of the movie"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/movies/{id}/restore [post]
+func (c *TrashController) RestoreMovie(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	movie, err := c.MovieService.RestoreMovie(id, middleware.GetActor(ctx))
+	if err != nil {
+		respondTrashError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", movieETag(movie))
+	ctx.JSON(http.StatusOK, toMovieResponse(movie))
+}
+
+// @Summary Permanently delete a movie
+// @Security BearerAuth
+// @Description Delete a movie, in the trash or not, together with its reviews, credits, list entries and history. This cannot be undone. Admin only.
+// @Accept json
+// @Produce json
+// @Tags Trash
+// @Param id path string true "Movie ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/admin/movies/{id} [delete]
+func (c *TrashController) PurgeMovie(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.MovieService.PurgeMovie(id); err != nil {
+		respondTrashError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Movie permanently deleted"})
+}
+
+func respondTrashError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMovieNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+	case errors.Is(err, services.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "You don't have permission to restore this movie"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type UserController struct {
+	UserService *services.UserService
+}
+
+func NewUserController(userService *services.UserService) *UserController {
+	return &UserController{
+		UserService: userService,
+	}
+}
+
+// @Summary Change a user's role
+// @Security BearerAuth
+// @Description Assign the user, moderator or admin role to a user. Admin only.
+// @Accept json
+// @Produce json
+// @Tags Admin
+// @Param id path string true "User ID"
+// @Param role body models.UserRoleRequest true "New role"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/admin/users/{id}/role [put]
+func (c *UserController) UpdateUserRole(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var request models.UserRoleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := c.UserService.UpdateRole(uint(id), request.Role)
+	if err != nil {
+		respondUserError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toUserResponse(user))
+}
+
+func respondUserError(ctx *gin.Context, err error) {
+	if errors.Is(err, services.ErrUserNotFound) {
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "User not found"})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+}
+
+func toUserResponse(user models.User) models.UserResponse {
+	return models.UserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          user.Role,
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     user.UpdatedAt.Format(time.RFC3339),
+	}
+}
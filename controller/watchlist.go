@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+)
+
+type WatchlistController struct {
+	WatchlistService *services.WatchlistService
+}
+
+func NewWatchlistController(watchlistService *services.WatchlistService) *WatchlistController {
+	return &WatchlistController{
+		WatchlistService: watchlistService,
+	}
+}
+
+// @Summary Get my watchlist
+// @Security BearerAuth
+// @Description Get a page of the authenticated user's watchlist in order
+// @Accept json
+// @Produce json
+// @Tags Watchlist
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.Watchlist
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/me/watchlist [get]
+func (c *WatchlistController) GetWatchlist(ctx *gin.Context) {
+	var page models.PageQuery
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	entries, total, err := c.WatchlistService.ListWatchlist(middleware.GetUserID(ctx), &page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	responses := make([]models.WatchlistEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = toWatchlistEntryResponse(entry)
+	}
+
+	ctx.JSON(http.StatusOK, models.Watchlist{
+		Entries:    responses,
+		Pagination: newPagination(page, total),
+	})
+}
+
+// @Summary Add a movie to my watchlist
+// @Security BearerAuth
+// @Description Add a movie at the given position, or at the end when no position is given
+// @Accept json
+// @Produce json
+// @Tags Watchlist
+// @Param entry body models.WatchlistRequest true "Movie and optional position"
+// @Success 201 {object} models.WatchlistEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/me/watchlist [post]
+func (c *WatchlistController) AddToWatchlist(ctx *gin.Context) {
+	var request models.WatchlistRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	entry, err := c.WatchlistService.AddToWatchlist(middleware.GetUserID(ctx), request.MovieID, request.Position)
+	if err != nil {
+		respondWatchlistError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toWatchlistEntryResponse(entry))
+}
+
+// @Summary Move a movie within my watchlist
+// @Security BearerAuth
+// @Description Move a movie to a new position, shifting the entries in between
+// @Accept json
+// @Produce json
+// @Tags Watchlist
+// @Param movieId path string true "Movie ID"
+// @Param position body models.WatchlistPositionRequest true "New position (1-based)"
+// @Success 200 {object} models.WatchlistEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/me/watchlist/{movieId}/position [put]
+func (c *WatchlistController) MoveWatchlistEntry(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "movieId")
+	if !ok {
+		return
+	}
+
+	var request models.WatchlistPositionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	entry, err := c.WatchlistService.MoveWatchlistEntry(middleware.GetUserID(ctx), movieID, request.Position)
+	if err != nil {
+		respondWatchlistError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toWatchlistEntryResponse(entry))
+}
+
+// @Summary Remove a movie from my watchlist
+// @Security BearerAuth
+// @Description Remove a movie from the authenticated user's watchlist
+// @Accept json
+// @Produce json
+// @Tags Watchlist
+// @Param movieId path string true "Movie ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/me/watchlist/{movieId} [delete]
+func (c *WatchlistController) RemoveFromWatchlist(ctx *gin.Context) {
+	movieID, ok := parseIDParam(ctx, "movieId")
+	if !ok {
+		return
+	}
+
+	if err := c.WatchlistService.RemoveFromWatchlist(middleware.GetUserID(ctx), movieID); err != nil {
+		respondWatchlistError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.MessageResponse{Message: "Movie removed from watchlist"})
+}
+
+func respondWatchlistError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMovieNotFound):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Movie not found"})
+	case errors.Is(err, services.ErrNotOnWatchlist):
+		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrWatchlistEntryExists):
+		ctx.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+func toWatchlistEntryResponse(entry models.WatchlistEntry) models.WatchlistEntryResponse {
+	return models.WatchlistEntryResponse{
+		Position: entry.Position,
+		AddedAt:  entry.CreatedAt,
+		Movie:    toMovieResponse(entry.Movie),
+	}
+}
@@ -1,10 +1,18 @@
 package core
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
 	"github.com/dostonshernazarov/movies-app/config"
 	controllers "github.com/dostonshernazarov/movies-app/controller"
 	_ "github.com/dostonshernazarov/movies-app/docs"
 	"github.com/dostonshernazarov/movies-app/middleware"
+	"github.com/dostonshernazarov/movies-app/migrations"
+	"github.com/dostonshernazarov/movies-app/models"
 	"github.com/dostonshernazarov/movies-app/repositories"
 	"github.com/dostonshernazarov/movies-app/services"
 	"github.com/gin-gonic/gin"
@@ -14,40 +22,145 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// App is the HTTP server with the background jobs it runs.
 type App struct {
-	Engine *gin.Engine
+	fx *fx.App
 }
 
-func (a *App) Run(addr string) error {
-	return a.Engine.Run(addr)
+// Run starts the app and blocks until SIGINT or SIGTERM, then shuts it down
+// gracefully.
+func (a *App) Run() error {
+	// Subscribe first, as fx only handles signals once Wait is called.
+	shutdowns := a.fx.Wait()
+
+	startCtx, cancel := context.WithTimeout(context.Background(), a.fx.StartTimeout())
+	defer cancel()
+	if err := a.fx.Start(startCtx); err != nil {
+		return err
+	}
+
+	shutdown := <-shutdowns
+	log.Printf("Received %s, stopping", shutdown.Signal)
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.fx.StopTimeout())
+	defer cancel()
+	if err := a.fx.Stop(stopCtx); err != nil {
+		return err
+	}
+	if shutdown.ExitCode != 0 {
+		return fmt.Errorf("stopped with exit code %d", shutdown.ExitCode)
+	}
+	return nil
 }
 
-func InitializeApp() (*App, error) {
-	var app App
+// Module provides the database connection, repositories and services shared
+// by the server and the command-line tools. fx only calls the constructors
+// that an invoked function needs, directly or through its dependencies.
+var Module = fx.Options(
+	// Provide database connection
+	fx.Provide(config.NewDatabaseConnection),
+	fx.Invoke(closeDatabase),
 
-	err := fx.New(
-		// Provide database connection
-		fx.Provide(config.NewDatabaseConnection),
+	// Provide repositories
+	fx.Provide(repositories.NewMovieRepository),
+	fx.Provide(repositories.NewUserRepository),
+	fx.Provide(repositories.NewRefreshTokenRepository),
+	fx.Provide(repositories.NewRevokedTokenRepository),
+	fx.Provide(repositories.NewPasswordResetRepository),
+	fx.Provide(repositories.NewReviewRepository),
+	fx.Provide(repositories.NewGenreRepository),
+	fx.Provide(repositories.NewPersonRepository),
+	fx.Provide(repositories.NewWatchlistRepository),
+	fx.Provide(repositories.NewDiaryRepository),
+	fx.Provide(repositories.NewListRepository),
+	fx.Provide(repositories.NewRevisionRepository),
 
-		// Provide repositories
-		fx.Provide(repositories.NewMovieRepository),
-		fx.Provide(repositories.NewUserRepository),
+	// Provide services
+	fx.Provide(services.NewJWTService),
+	fx.Provide(services.NewRevocationStore),
+	fx.Provide(services.NewMailer),
+	fx.Provide(services.NewBlobStore),
+	fx.Provide(services.NewMovieService),
+	fx.Provide(services.NewAuthService),
+	fx.Provide(services.NewUserService),
+	fx.Provide(services.NewReviewService),
+	fx.Provide(services.NewGenreService),
+	fx.Provide(services.NewPersonService),
+	fx.Provide(services.NewWatchlistService),
+	fx.Provide(services.NewDiaryService),
+	fx.Provide(services.NewListService),
+	fx.Provide(services.NewRevisionService),
+	fx.Provide(services.NewTrashPurger),
+	fx.Provide(services.NewImageService),
+	fx.Provide(services.NewReadiness),
+	fx.Provide(services.NewHealthRegistry),
+)
 
-		// Provide services
-		fx.Provide(services.NewJWTService),
-		fx.Provide(services.NewMovieService),
-		fx.Provide(services.NewAuthService),
+func InitializeApp(cfg *config.Config) (*App, error) {
+	app := fx.New(
+		fx.Supply(cfg),
+		Module,
 
 		// Provide controllers
 		fx.Provide(controllers.NewAuthController),
 		fx.Provide(controllers.NewMovieController),
+		fx.Provide(controllers.NewUserController),
+		fx.Provide(controllers.NewReviewController),
+		fx.Provide(controllers.NewGenreController),
+		fx.Provide(controllers.NewPersonController),
+		fx.Provide(controllers.NewWatchlistController),
+		fx.Provide(controllers.NewDiaryController),
+		fx.Provide(controllers.NewListController),
+		fx.Provide(controllers.NewRevisionController),
+		fx.Provide(controllers.NewTrashController),
+		fx.Provide(controllers.NewImageController),
+		fx.Provide(controllers.NewHealthController),
 
 		fx.Provide(NewGinEngine),
+		fx.Provide(NewHTTPServer),
+
+		// Refuse to serve an outdated schema
+		fx.Invoke(migrations.CheckSchema),
+		fx.Invoke(registerHealthChecks),
 
-		fx.Populate(&app.Engine),
-	).Err()
+		// Stop hooks run in reverse, so the server drains before pending
+		// emails, the purger and the database pool are stopped.
+		fx.Invoke(runTrashPurger),
+		fx.Invoke(waitForEmails),
+		fx.Invoke(func(*http.Server) {}),
 
-	return &app, err
+		// Leave room for the delay, the drain and the other stop hooks.
+		fx.StopTimeout(cfg.Server.ShutdownDelay+cfg.Server.ShutdownTimeout+10*time.Second),
+	)
+	if err := app.Err(); err != nil {
+		return nil, err
+	}
+
+	return &App{fx: app}, nil
+}
+
+// Run calls fn, a function taking its dependencies as arguments and
+// optionally returning an error, once the schema is known to be current.
+// Only the parts of Module that fn needs are built, and they are stopped
+// again, closing the database pool, once fn returns.
+func Run(cfg *config.Config, fn interface{}) error {
+	app := fx.New(
+		fx.Supply(cfg),
+		Module,
+		fx.NopLogger,
+		fx.Invoke(migrations.CheckSchema),
+		fx.Invoke(fn),
+	)
+	if err := app.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), app.StopTimeout())
+	defer cancel()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	return app.Stop(ctx)
 }
 
 // NewGinEngine creates and configures the Gin engine with routes
@@ -66,29 +179,145 @@ func InitializeApp() (*App, error) {
 func NewGinEngine(
 	movieController *controllers.MovieController,
 	authController *controllers.AuthController,
+	userController *controllers.UserController,
+	reviewController *controllers.ReviewController,
+	genreController *controllers.GenreController,
+	personController *controllers.PersonController,
+	watchlistController *controllers.WatchlistController,
+	diaryController *controllers.DiaryController,
+	listController *controllers.ListController,
+	revisionController *controllers.RevisionController,
+	trashController *controllers.TrashController,
+	imageController *controllers.ImageController,
+	healthController *controllers.HealthController,
+	authService *services.AuthService,
+	blobStore services.BlobStore,
 ) *gin.Engine {
 	engine := gin.Default()
 
 	engine.Use(middleware.CORSMiddleware())
 
+	engine.GET("/healthz", healthController.Live)
+	engine.GET("/readyz", healthController.Ready)
+
+	authMiddleware := middleware.JWTAuthMiddleware(authService)
+
 	// Auth routes
 	authRoutes := engine.Group("/auth")
 	{
 		authRoutes.POST("/register", authController.Register)
 		authRoutes.POST("/login", authController.Login)
+		authRoutes.POST("/refresh", authController.Refresh)
+		authRoutes.POST("/logout", authMiddleware, authController.Logout)
+		authRoutes.POST("/password/forgot", authController.ForgotPassword)
+		authRoutes.POST("/password/reset", authController.ResetPassword)
+		authRoutes.GET("/verify", authController.VerifyEmail)
+		authRoutes.POST("/verify/resend", authMiddleware, authController.ResendVerification)
 	}
 
 	apiRoutes := engine.Group("/api")
-	apiRoutes.Use(middleware.JWTAuthMiddleware())
+	apiRoutes.Use(authMiddleware, middleware.RequireVerifiedEmail())
 	{
 		movies := apiRoutes.Group("/movies")
 		{
 			movies.GET("", movieController.GetAllMovies)
+			movies.GET("/search", movieController.SearchMovies)
+			movies.GET("/export", movieController.ExportMovies)
 			movies.GET("/:id", movieController.GetMovieByID)
 			movies.POST("", movieController.CreateMovie)
+			movies.POST("/import", movieController.ImportMovies)
 			movies.PUT("/:id", movieController.UpdateMovie)
+			movies.PATCH("/:id", movieController.PatchMovie)
 			movies.DELETE("/:id", movieController.DeleteMovie)
+
+			movies.GET("/:id/reviews", reviewController.GetReviews)
+			movies.POST("/:id/reviews", reviewController.CreateReview)
+			movies.GET("/:id/reviews/:reviewId", reviewController.GetReview)
+			movies.PUT("/:id/reviews/:reviewId", reviewController.UpdateReview)
+			movies.DELETE("/:id/reviews/:reviewId", reviewController.DeleteReview)
+
+			movies.GET("/:id/history", revisionController.GetHistory)
+			movies.GET("/:id/history/diff", revisionController.DiffRevisions)
+			movies.GET("/:id/history/:rev", revisionController.GetRevision)
+			movies.POST("/:id/revert/:rev", revisionController.RevertMovie)
+			movies.POST("/:id/restore", trashController.RestoreMovie)
+
+			movies.POST("/:id/poster", imageController.UploadPoster)
+			movies.DELETE("/:id/poster", imageController.DeletePoster)
+			movies.POST("/:id/backdrop", imageController.UploadBackdrop)
+			movies.DELETE("/:id/backdrop", imageController.DeleteBackdrop)
+
+			movies.GET("/:id/credits", personController.GetMovieCredits)
+			movies.POST("/:id/credits", personController.AddMovieCredit)
+			movies.DELETE("/:id/credits/:creditId", personController.DeleteMovieCredit)
 		}
+
+		people := apiRoutes.Group("/people")
+		{
+			people.GET("", personController.GetPeople)
+			people.POST("", personController.CreatePerson)
+			people.GET("/:id", personController.GetPerson)
+			people.PUT("/:id", personController.UpdatePerson)
+			people.DELETE("/:id", personController.DeletePerson)
+			people.GET("/:id/filmography", personController.GetFilmography)
+		}
+
+		apiRoutes.GET("/genres", genreController.GetGenres)
+
+		lists := apiRoutes.Group("/lists")
+		{
+			lists.GET("", listController.GetPublicLists)
+			lists.POST("", listController.CreateList)
+			lists.GET("/:id", listController.GetList)
+			lists.PUT("/:id", listController.UpdateList)
+			lists.DELETE("/:id", listController.DeleteList)
+			lists.POST("/:id/share-token", listController.RotateShareToken)
+
+			lists.GET("/:id/items", listController.GetListItems)
+			lists.POST("/:id/items", listController.AddListItem)
+			lists.PUT("/:id/items/:movieId", listController.UpdateListItem)
+			lists.DELETE("/:id/items/:movieId", listController.RemoveListItem)
+
+			lists.PUT("/:id/collaborators/:userId", listController.SaveCollaborator)
+			lists.DELETE("/:id/collaborators/:userId", listController.RemoveCollaborator)
+		}
+
+		me := apiRoutes.Group("/me")
+		{
+			me.GET("/movies", movieController.GetMyMovies)
+
+			me.GET("/watchlist", watchlistController.GetWatchlist)
+			me.POST("/watchlist", watchlistController.AddToWatchlist)
+			me.PUT("/watchlist/:movieId/position", watchlistController.MoveWatchlistEntry)
+			me.DELETE("/watchlist/:movieId", watchlistController.RemoveFromWatchlist)
+
+			me.GET("/lists", listController.GetMyLists)
+			me.GET("/trash", trashController.GetTrash)
+
+			me.GET("/diary", diaryController.GetDiary)
+			me.POST("/diary", diaryController.LogViewing)
+			me.GET("/diary/:entryId", diaryController.GetDiaryEntry)
+			me.PUT("/diary/:entryId", diaryController.UpdateDiaryEntry)
+			me.DELETE("/diary/:entryId", diaryController.DeleteDiaryEntry)
+		}
+
+		admin := apiRoutes.Group("/admin")
+		admin.Use(middleware.RequireRole(models.RoleAdmin))
+		{
+			admin.PUT("/users/:id/role", userController.UpdateUserRole)
+			admin.POST("/users/:id/sessions/revoke", authController.RevokeUserSessions)
+			admin.DELETE("/movies/:id", trashController.PurgeMovie)
+		}
+	}
+
+	publicRoutes := engine.Group("/api")
+	{
+		publicRoutes.GET("/users/:username/movies", movieController.GetUserMovies)
+	}
+
+	// Uploaded images are served by the app itself when stored locally.
+	if local, ok := blobStore.(*services.LocalBlobStore); ok {
+		engine.Static(services.LocalMediaRoute, local.Dir)
 	}
 
 	url := ginSwagger.URL("swagger/doc.json")
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/config"
+	"github.com/dostonshernazarov/movies-app/migrations"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// NewHTTPServer returns the server for engine and ties it to the app's
+// lifecycle. It listens when the app starts. When the app stops, readiness
+// fails at once, the server keeps serving for the shutdown delay so load
+// balancers can react, then waits up to the shutdown timeout for in-flight
+// requests before closing their connections.
+func NewHTTPServer(lc fx.Lifecycle, shutdowner fx.Shutdowner, cfg *config.Config, engine *gin.Engine, readiness *services.Readiness) *http.Server {
+	server := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      engine,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// Listen before returning so that a taken port fails the start.
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+
+			log.Printf("Listening on %s", listener.Addr())
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Printf("HTTP server failed: %v", err)
+					shutdowner.Shutdown(fx.ExitCode(1))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			readiness.Drain()
+			if delay := cfg.Server.ShutdownDelay; delay > 0 {
+				log.Printf("Failing readiness for %s before shutting down", delay)
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+				}
+			}
+
+			log.Printf("Shutting down, waiting up to %s for requests to finish", cfg.Server.ShutdownTimeout)
+			drainCtx, cancel := context.WithTimeout(ctx, cfg.Server.ShutdownTimeout)
+			defer cancel()
+			if err := server.Shutdown(drainCtx); err != nil {
+				log.Printf("Requests still running at the shutdown timeout were cut off: %v", err)
+				return server.Close()
+			}
+			return nil
+		},
+	})
+
+	return server
+}
+
+// runTrashPurger purges the trash in the background while the app runs.
+func runTrashPurger(lc fx.Lifecycle, purger *services.TrashPurger) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				purger.Run(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+// waitForEmails lets emails sent in the background finish when the app
+// stops, before the database pool they use is closed.
+func waitForEmails(lc fx.Lifecycle, auth *services.AuthService) {
+	lc.Append(fx.Hook{
+		OnStop: auth.WaitForEmails,
+	})
+}
+
+// registerHealthChecks adds the app's dependencies to the readiness probe:
+// the database, the schema version and any dependency that can check itself,
+// such as the SMTP mailer or the S3 store.
+func registerHealthChecks(registry *services.HealthRegistry, db *gorm.DB, mailer services.Mailer, store services.BlobStore) error {
+	runner, err := migrations.NewRunner(db)
+	if err != nil {
+		return err
+	}
+
+	registry.Register(services.NewDatabaseCheck(db))
+	registry.Register(services.NewHealthCheck("migrations", func(ctx context.Context) error {
+		current := *runner
+		current.DB = db.WithContext(ctx)
+		return current.RequireCurrent()
+	}))
+
+	for _, dependency := range []interface{}{mailer, store} {
+		if checker, ok := dependency.(services.HealthChecker); ok {
+			registry.Register(checker)
+		}
+	}
+	return nil
+}
+
+// closeDatabase closes the connection pool when the app stops. It is
+// registered before anything else, so it runs after every other stop hook.
+func closeDatabase(lc fx.Lifecycle, db *gorm.DB) {
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		},
+	})
+}
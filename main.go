@@ -1,30 +1,82 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/dostonshernazarov/movies-app/config"
-	"github.com/dostonshernazarov/movies-app/core"
 	"github.com/joho/godotenv"
 )
 
+const usage = `usage: movies-app [settings] [command] [flags]
+
+commands:
+  serve                start the HTTP server (the default)
+  migrate              manage the database schema
+  seed                 load sample movies from a file
+  user create          add a user account
+  user reset-password  set a user's password
+  token issue          issue an access token for a service account
+
+Settings come from a YAML file, then environment variables, then the flags
+below. Run "movies-app <command> -h" for the flags of a command.`
+
+// commands maps each command name to the function that runs it with the
+// configuration and the remaining arguments.
+var commands = map[string]func(cfg *config.Config, args []string) error{
+	"serve":   runServe,
+	"migrate": runMigrate,
+	"seed":    runSeed,
+	"user":    runUser,
+	"token":   runToken,
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	app, err := core.InitializeApp()
+	flags := newFlagSet("movies-app", usage)
+	cfg, err := config.Load(flags, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatalf("Failed to initialize application: %v", err)
+		log.Fatal(err)
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = config.DefaultPort
+	name, args := "serve", flags.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
 	}
+	if name == "help" {
+		fmt.Println(usage)
+		return
+	}
+
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", name, usage)
+		os.Exit(2)
+	}
+	if err := run(cfg, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatalf("%s: %v", name, err)
+	}
+}
 
-	if err := app.Run(":" + port); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
+// newFlagSet returns the flag set of a command, which prints usage and the
+// flag defaults on -h or a parse error.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), usage)
+		flags.PrintDefaults()
 	}
+	return flags
 }
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -9,9 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func JWTAuthMiddleware() gin.HandlerFunc {
-	jwtService := services.NewJWTService()
-
+func JWTAuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
@@ -24,15 +23,22 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			authHeader = strings.TrimPrefix(authHeader, "Bearer ")
 		}
 
-		token, err := jwtService.ValidateToken(authHeader)
-		if err != nil || !token.Valid {
-			ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid or expired token"})
+		claims, err := authService.Authenticate(authHeader)
+		if err != nil {
+			if errors.Is(err, services.ErrAccessTokenRevoked) {
+				ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Token has been revoked"})
+			} else if errors.Is(err, services.ErrInvalidAccessToken) {
+				ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid or expired token"})
+			} else {
+				ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+			}
 			ctx.Abort()
 			return
 		}
 
-		userID := jwtService.ExtractUserID(token)
-		ctx.Set("user_id", userID)
+		ctx.Set("user_id", claims.UserID)
+		ctx.Set("role", claims.Role)
+		ctx.Set("claims", claims)
 
 		ctx.Next()
 	}
@@ -45,3 +51,63 @@ func GetUserID(ctx *gin.Context) uint {
 	}
 	return userID.(uint)
 }
+
+func GetUserRole(ctx *gin.Context) string {
+	role, exists := ctx.Get("role")
+	if !exists {
+		return ""
+	}
+	return role.(string)
+}
+
+func GetClaims(ctx *gin.Context) models.AccessClaims {
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		return models.AccessClaims{}
+	}
+	return claims.(models.AccessClaims)
+}
+
+func GetActor(ctx *gin.Context) models.Actor {
+	return models.Actor{
+		UserID: GetUserID(ctx),
+		Role:   GetUserRole(ctx),
+	}
+}
+
+// RequireRole aborts with 403 unless the authenticated user has one of the
+// given roles. It must run after JWTAuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		role := GetUserRole(ctx)
+		for _, allowed := range roles {
+			if role == allowed {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Insufficient role"})
+		ctx.Abort()
+	}
+}
+
+// RequireVerifiedEmail limits users with an unverified email address to
+// read-only requests. It must run after JWTAuthMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
+			return
+		}
+
+		if !GetClaims(ctx).EmailVerified {
+			ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Email address must be verified"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/config"
+	"github.com/dostonshernazarov/movies-app/migrations"
+)
+
+const migrateUsage = `usage: movies-app migrate [-dir <dir>] <command>
+
+commands:
+  up             apply all pending migrations
+  down [steps]   revert the latest migrations (default 1)
+  status         list migrations and when they were applied
+  create <name>  add empty up and down files to the migrations directory`
+
+// runMigrate implements the migrate command.
+func runMigrate(cfg *config.Config, args []string) error {
+	flags := newFlagSet("migrate", migrateUsage)
+	dir := flags.String("dir", "migrations", "directory for new migration files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("missing migrate command")
+	}
+
+	command, rest := flags.Arg(0), flags.Args()[1:]
+	if command == "create" {
+		if len(rest) != 1 {
+			return errors.New("usage: movies-app migrate create <name>")
+		}
+		up, down, err := migrations.Create(*dir, rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\nCreated %s\n", up, down)
+		return nil
+	}
+
+	runner, err := migrations.NewRunner(config.NewDatabaseConnection(cfg))
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := runner.Up()
+		for _, migration := range applied {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(rest) > 0 {
+			if steps, err = strconv.Atoi(rest[0]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", rest[0])
+			}
+		}
+		reverted, err := runner.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		statuses, err := runner.Status()
+		if err != nil {
+			return err
+		}
+		return printMigrationStatus(statuses)
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
+
+func printMigrationStatus(statuses []migrations.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+			switch {
+			case status.Up == "":
+				state = "applied, unknown to this binary"
+			case status.Changed:
+				state = "applied, file changed since"
+			default:
+				state = "applied"
+			}
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
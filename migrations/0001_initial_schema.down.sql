@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS "movie_revisions";
+DROP FUNCTION IF EXISTS movie_revisions_immutable();
+DROP TABLE IF EXISTS "list_collaborators";
+DROP TABLE IF EXISTS "list_items";
+DROP TABLE IF EXISTS "lists";
+DROP TABLE IF EXISTS "diary_entries";
+DROP TABLE IF EXISTS "watchlist_entries";
+DROP TABLE IF EXISTS "credits";
+DROP TABLE IF EXISTS "people";
+DROP TABLE IF EXISTS "reviews";
+DROP TABLE IF EXISTS "password_reset_tokens";
+DROP TABLE IF EXISTS "revoked_tokens";
+DROP TABLE IF EXISTS "refresh_tokens";
+DROP TABLE IF EXISTS "movie_genres";
+DROP TABLE IF EXISTS "genres";
+DROP TABLE IF EXISTS "movies";
+DROP TABLE IF EXISTS "users";
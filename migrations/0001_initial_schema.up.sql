@@ -0,0 +1,270 @@
+-- Baseline schema. Every statement is guarded so that databases created by
+-- the previous AutoMigrate-based releases can adopt it unchanged.
+
+CREATE TABLE IF NOT EXISTS "users" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"username" varchar(255) NOT NULL,
+	"password" varchar(255) NOT NULL,
+	"email" varchar(255) NOT NULL,
+	"role" varchar(20) NOT NULL DEFAULT 'user',
+	"tokens_invalid_before" timestamptz,
+	"email_verified_at" timestamptz,
+	"verification_sent_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "uni_users_username" UNIQUE ("username"),
+	CONSTRAINT "uni_users_email" UNIQUE ("email")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+-- Columns added after the original users table. Accounts that predate email
+-- verification are treated as verified, so the backfill only runs when the
+-- column is first added.
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "role" varchar(20) NOT NULL DEFAULT 'user';
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "tokens_invalid_before" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "verification_sent_at" timestamptz;
+DO $$
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema()
+			AND table_name = 'users' AND column_name = 'email_verified_at'
+	) THEN
+		ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamptz;
+		UPDATE "users" SET "email_verified_at" = "created_at" WHERE "email_verified_at" IS NULL;
+	END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS "movies" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"title" varchar(255) NOT NULL,
+	"director" varchar(255) NOT NULL,
+	"year" bigint,
+	"plot" text,
+	"genre" varchar(100),
+	"rating" decimal,
+	"version" bigint NOT NULL DEFAULT 1,
+	"poster" jsonb,
+	"backdrop" jsonb,
+	"average_rating" decimal NOT NULL DEFAULT 0,
+	"vote_count" bigint NOT NULL DEFAULT 0,
+	"user_id" bigint,
+	"modified_by_id" bigint,
+	"modified_via" varchar(20),
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_movies_deleted_at" ON "movies" ("deleted_at");
+
+-- Columns added after the original movies table.
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "version" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "poster" jsonb;
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "backdrop" jsonb;
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "average_rating" decimal NOT NULL DEFAULT 0;
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "vote_count" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "modified_by_id" bigint;
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "modified_via" varchar(20);
+
+-- A generated column, so PostgreSQL keeps it in sync on every write.
+ALTER TABLE "movies" ADD COLUMN IF NOT EXISTS "search_vector" tsvector
+	GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(director, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(genre, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(plot, '')), 'C')
+	) STORED;
+CREATE INDEX IF NOT EXISTS "idx_movies_search_vector" ON "movies" USING GIN ("search_vector");
+
+CREATE TABLE IF NOT EXISTS "genres" (
+	"id" bigserial,
+	"name" varchar(100) NOT NULL,
+	"slug" varchar(100) NOT NULL,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_genres_slug" ON "genres" ("slug");
+
+CREATE TABLE IF NOT EXISTS "movie_genres" (
+	"movie_id" bigint,
+	"genre_id" bigint,
+	PRIMARY KEY ("movie_id", "genre_id"),
+	CONSTRAINT "fk_movie_genres_movie" FOREIGN KEY ("movie_id") REFERENCES "movies"("id"),
+	CONSTRAINT "fk_movie_genres_genre" FOREIGN KEY ("genre_id") REFERENCES "genres"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"family_id" varchar(64) NOT NULL,
+	"token_hash" varchar(64) NOT NULL,
+	"expires_at" timestamptz NOT NULL,
+	"revoked_at" timestamptz,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_family_id" ON "refresh_tokens" ("family_id");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "revoked_tokens" (
+	"token_id" varchar(64),
+	"expires_at" timestamptz NOT NULL,
+	"created_at" timestamptz,
+	PRIMARY KEY ("token_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_expires_at" ON "revoked_tokens" ("expires_at");
+
+CREATE TABLE IF NOT EXISTS "password_reset_tokens" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"token_hash" varchar(64) NOT NULL,
+	"expires_at" timestamptz NOT NULL,
+	"used_at" timestamptz,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_user_id" ON "password_reset_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "reviews" (
+	"id" bigserial,
+	"movie_id" bigint NOT NULL,
+	"user_id" bigint NOT NULL,
+	"score" bigint NOT NULL,
+	"text" text,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_reviews_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reviews_user_id" ON "reviews" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_reviews_movie_user" ON "reviews" ("movie_id", "user_id");
+
+CREATE TABLE IF NOT EXISTS "people" (
+	"id" bigserial,
+	"name" varchar(255) NOT NULL,
+	"bio" text,
+	"birth_date" date,
+	"created_by_id" bigint,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_people_name" ON "people" ("name");
+
+CREATE TABLE IF NOT EXISTS "credits" (
+	"id" bigserial,
+	"movie_id" bigint NOT NULL,
+	"person_id" bigint NOT NULL,
+	"role" varchar(20) NOT NULL,
+	"character" varchar(255),
+	"position" bigint NOT NULL DEFAULT 0,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_credits_person" FOREIGN KEY ("person_id") REFERENCES "people"("id"),
+	CONSTRAINT "fk_credits_movie" FOREIGN KEY ("movie_id") REFERENCES "movies"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_credits_person_id" ON "credits" ("person_id");
+CREATE INDEX IF NOT EXISTS "idx_credits_movie_id" ON "credits" ("movie_id");
+
+CREATE TABLE IF NOT EXISTS "watchlist_entries" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"movie_id" bigint NOT NULL,
+	"position" bigint NOT NULL,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_watchlist_entries_movie" FOREIGN KEY ("movie_id") REFERENCES "movies"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_watchlist_entries_movie_id" ON "watchlist_entries" ("movie_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_watchlist_user_movie" ON "watchlist_entries" ("user_id", "movie_id");
+
+CREATE TABLE IF NOT EXISTS "diary_entries" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"movie_id" bigint NOT NULL,
+	"watched_on" date NOT NULL,
+	"rewatch" boolean NOT NULL DEFAULT false,
+	"notes" text,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_diary_entries_movie" FOREIGN KEY ("movie_id") REFERENCES "movies"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_diary_entries_movie_id" ON "diary_entries" ("movie_id");
+CREATE INDEX IF NOT EXISTS "idx_diary_entries_user_id" ON "diary_entries" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "lists" (
+	"id" bigserial,
+	"user_id" bigint NOT NULL,
+	"title" varchar(255) NOT NULL,
+	"description" text,
+	"visibility" varchar(20) NOT NULL DEFAULT 'private',
+	"share_token" varchar(64) NOT NULL,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"modified_by_id" bigint,
+	"modified_via" varchar(20),
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_lists_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_lists_user_id" ON "lists" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_lists_share_token" ON "lists" ("share_token");
+CREATE INDEX IF NOT EXISTS "idx_lists_visibility" ON "lists" ("visibility");
+
+CREATE TABLE IF NOT EXISTS "list_items" (
+	"id" bigserial,
+	"list_id" bigint NOT NULL,
+	"movie_id" bigint NOT NULL,
+	"position" bigint NOT NULL,
+	"note" text,
+	"added_by_id" bigint,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_list_items_movie" FOREIGN KEY ("movie_id") REFERENCES "movies"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_list_items_movie_id" ON "list_items" ("movie_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_list_items_list_movie" ON "list_items" ("list_id", "movie_id");
+
+CREATE TABLE IF NOT EXISTS "list_collaborators" (
+	"id" bigserial,
+	"list_id" bigint NOT NULL,
+	"user_id" bigint NOT NULL,
+	"role" varchar(20) NOT NULL,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_lists_collaborators" FOREIGN KEY ("list_id") REFERENCES "lists"("id"),
+	CONSTRAINT "fk_list_collaborators_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_list_collaborators_user_id" ON "list_collaborators" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_list_collaborators_list_user" ON "list_collaborators" ("list_id", "user_id");
+
+CREATE TABLE IF NOT EXISTS "movie_revisions" (
+	"id" bigserial,
+	"movie_id" bigint NOT NULL,
+	"revision" bigint NOT NULL,
+	"action" varchar(20) NOT NULL,
+	"snapshot" jsonb NOT NULL,
+	"editor_id" bigint NOT NULL,
+	"reverted_from" bigint,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_movie_revisions_editor" FOREIGN KEY ("editor_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_movie_revisions_movie_revision" ON "movie_revisions" ("movie_id", "revision");
+
+-- Revisions are read-only; only purging a movie may delete them.
+CREATE OR REPLACE FUNCTION movie_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'movie revisions cannot be modified';
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS movie_revisions_immutable ON movie_revisions;
+CREATE TRIGGER movie_revisions_immutable BEFORE UPDATE ON movie_revisions
+	FOR EACH ROW EXECUTE FUNCTION movie_revisions_immutable();
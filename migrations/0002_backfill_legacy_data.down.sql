@@ -0,0 +1,2 @@
+-- The backfilled genres, people, credits and revisions are ordinary data
+-- by now, so they are kept.
@@ -0,0 +1,120 @@
+-- Moves data written before genres, people and edit history existed into
+-- their tables. Rows that were already migrated are skipped, so this is a
+-- no-op on new databases.
+
+-- Split the free-text genre column of movies without linked genres into
+-- canonical slugs and names. The aliases mirror services.genreAliases.
+CREATE TEMP TABLE legacy_genres ON COMMIT DROP AS
+WITH aliases (alias, slug, name) AS (VALUES
+	('sci-fi', 'science-fiction', 'Science Fiction'),
+	('scifi', 'science-fiction', 'Science Fiction'),
+	('sf', 'science-fiction', 'Science Fiction'),
+	('science-fiction', 'science-fiction', 'Science Fiction'),
+	('rom-com', 'romantic-comedy', 'Romantic Comedy'),
+	('romcom', 'romantic-comedy', 'Romantic Comedy'),
+	('romantic-comedy', 'romantic-comedy', 'Romantic Comedy'),
+	('doc', 'documentary', 'Documentary'),
+	('docu', 'documentary', 'Documentary'),
+	('documentary', 'documentary', 'Documentary'),
+	('animated', 'animation', 'Animation'),
+	('animation', 'animation', 'Animation'),
+	('noir', 'film-noir', 'Film Noir'),
+	('film-noir', 'film-noir', 'Film Noir'),
+	('bio', 'biography', 'Biography'),
+	('biopic', 'biography', 'Biography'),
+	('biography', 'biography', 'Biography'),
+	('musical', 'musical', 'Musical'),
+	('music', 'music', 'Music'),
+	('suspense', 'thriller', 'Thriller'),
+	('thriller', 'thriller', 'Thriller')
+),
+parts AS (
+	SELECT movies.id AS movie_id,
+		regexp_replace(trim(part), '\s+', ' ', 'g') AS name,
+		trim(both '-' FROM regexp_replace(lower(normalize(part, NFC)), '[^[:alnum:]]+', '-', 'g')) AS slug
+	FROM movies
+	CROSS JOIN LATERAL regexp_split_to_table(movies.genre, '[,/|;]') AS part
+	WHERE coalesce(movies.genre, '') <> ''
+		AND NOT EXISTS (SELECT 1 FROM movie_genres WHERE movie_genres.movie_id = movies.id)
+)
+SELECT parts.movie_id,
+	coalesce(aliases.slug, parts.slug) AS slug,
+	coalesce(aliases.name, initcap(parts.name)) AS name
+FROM parts
+LEFT JOIN aliases ON aliases.alias = parts.slug
+WHERE parts.slug <> '';
+
+INSERT INTO genres (name, slug, created_at)
+SELECT DISTINCT ON (slug) name, slug, now() FROM legacy_genres ORDER BY slug, name
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO movie_genres (movie_id, genre_id)
+SELECT DISTINCT legacy_genres.movie_id, genres.id
+FROM legacy_genres JOIN genres ON genres.slug = legacy_genres.slug
+ON CONFLICT DO NOTHING;
+
+-- Rewrite the text column with the canonical names.
+UPDATE movies SET genre = left((
+	SELECT string_agg(genres.name, ', ' ORDER BY genres.name)
+	FROM movie_genres JOIN genres ON genres.id = movie_genres.genre_id
+	WHERE movie_genres.movie_id = movies.id
+), 100)
+WHERE id IN (SELECT movie_id FROM legacy_genres);
+
+-- Split the director column of movies without director credits into
+-- individual names, in the same way as services.directorNames, then create
+-- people for them and credit them.
+CREATE TEMP TABLE legacy_directors ON COMMIT DROP AS
+SELECT movies.id AS movie_id, movies.user_id,
+	trim(part.name) AS name,
+	part.position - 1 AS position
+FROM movies
+CROSS JOIN LATERAL regexp_split_to_table(movies.director, '[,&;/]')
+	WITH ORDINALITY AS part (name, position)
+WHERE movies.deleted_at IS NULL
+	AND trim(part.name) <> ''
+	AND NOT EXISTS (
+		SELECT 1 FROM credits
+		WHERE credits.movie_id = movies.id AND credits.role = 'director'
+	);
+
+INSERT INTO people (name, bio, created_by_id, created_at, updated_at)
+SELECT DISTINCT ON (lower(name)) name, '', user_id, now(), now()
+FROM legacy_directors
+WHERE NOT EXISTS (SELECT 1 FROM people WHERE lower(people.name) = lower(legacy_directors.name))
+ORDER BY lower(name), movie_id;
+
+INSERT INTO credits (movie_id, person_id, role, "character", position, created_at)
+SELECT DISTINCT ON (legacy_directors.movie_id, person.id)
+	legacy_directors.movie_id, person.id, 'director', '', legacy_directors.position, now()
+FROM legacy_directors
+CROSS JOIN LATERAL (
+	SELECT people.id FROM people
+	WHERE lower(people.name) = lower(legacy_directors.name)
+	ORDER BY people.id LIMIT 1
+) AS person
+ORDER BY legacy_directors.movie_id, person.id, legacy_directors.position;
+
+-- Record the current state of movies that predate edit history as their
+-- first revision. The snapshot keys match models.MovieSnapshot.
+INSERT INTO movie_revisions (movie_id, revision, action, snapshot, editor_id, created_at)
+SELECT movies.id, 1, 'create', jsonb_build_object(
+		'title', movies.title,
+		'director', movies.director,
+		'year', movies.year,
+		'plot', coalesce(movies.plot, ''),
+		'genres', coalesce((
+			SELECT jsonb_agg(genres.name ORDER BY genres.name)
+			FROM movie_genres JOIN genres ON genres.id = movie_genres.genre_id
+			WHERE movie_genres.movie_id = movies.id
+		), '[]'::jsonb),
+		'rating', coalesce(movies.rating, 0),
+		'user_id', coalesce(movies.user_id, 0),
+		'modified_by_id', coalesce(movies.modified_by_id, 0),
+		'modified_via', coalesce(movies.modified_via, ''),
+		'created_at', movies.created_at,
+		'updated_at', movies.updated_at
+	), coalesce(movies.user_id, 0), now()
+FROM movies
+WHERE movies.deleted_at IS NULL
+	AND NOT EXISTS (SELECT 1 FROM movie_revisions WHERE movie_revisions.movie_id = movies.id);
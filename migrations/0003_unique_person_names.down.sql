@@ -0,0 +1,2 @@
+-- Merged people are not split again.
+DROP INDEX IF EXISTS "idx_people_lower_name";
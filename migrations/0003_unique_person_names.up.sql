@@ -0,0 +1,38 @@
+-- People are matched by name, ignoring case, when movies are credited from
+-- their director string. Merge people that share a name into the oldest
+-- record so the name can be made unique.
+CREATE TEMP TABLE duplicate_people ON COMMIT DROP AS
+SELECT people.id, keepers.id AS keeper_id
+FROM people
+JOIN (
+	SELECT lower(name) AS name, min(id) AS id FROM people GROUP BY lower(name)
+) AS keepers ON keepers.name = lower(people.name)
+WHERE people.id <> keepers.id;
+
+UPDATE credits SET person_id = duplicate_people.keeper_id
+FROM duplicate_people
+WHERE credits.person_id = duplicate_people.id;
+
+-- Merging can leave a person credited twice in the same role on a movie.
+DELETE FROM credits
+WHERE id IN (
+	SELECT id FROM (
+		SELECT id, row_number() OVER (
+			PARTITION BY movie_id, person_id, role, "character"
+			ORDER BY position, id
+		) AS duplicate
+		FROM credits
+		WHERE person_id IN (SELECT keeper_id FROM duplicate_people)
+	) AS ranked
+	WHERE duplicate > 1
+);
+
+UPDATE movies SET version = version + 1
+WHERE id IN (
+	SELECT movie_id FROM credits
+	WHERE person_id IN (SELECT keeper_id FROM duplicate_people)
+);
+
+DELETE FROM people WHERE id IN (SELECT id FROM duplicate_people);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_people_lower_name" ON "people" (lower("name"));
@@ -0,0 +1,353 @@
+// Package migrations applies the versioned SQL files in this directory to
+// the database. Each version has an up file and a down file named
+// <version>_<name>.up.sql and <version>_<name>.down.sql; they are embedded
+// in the binary and run in order, each in its own transaction.
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// lockID identifies the PostgreSQL advisory lock that serialises migration
+// runs, so replicas starting together do not apply the same version twice.
+const lockID = 4_182_736_055
+
+var (
+	fileName    = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	nameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum identifies the up script, so that edits to an applied migration
+// can be detected.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// Status describes a migration and whether it has been applied. Migrations
+// recorded in the database but unknown to this binary have an empty Up.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+	// Changed is set when the applied up script differs from the file.
+	Changed bool
+}
+
+// schemaMigration is a row of the schema_migrations table, which records
+// every applied version.
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+type Runner struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+// NewRunner returns a runner for the migrations embedded in the binary.
+func NewRunner(db *gorm.DB) (*Runner, error) {
+	migrations, err := Load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{DB: db, Migrations: migrations}, nil
+}
+
+// Load reads the migrations in the root of fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files named %q and %q", version, migration.Name, match[2])
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("migration %d_%s needs non-empty up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies every pending migration and returns the ones it applied.
+func (r *Runner) Up() ([]Migration, error) {
+	var applied []Migration
+	err := r.locked(func(conn *gorm.DB) error {
+		pending, err := r.pending(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := exec(tx, migration.Up); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					Checksum:  migration.Checksum(),
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the latest steps applied migrations, newest first, and
+// returns the ones it reverted.
+func (r *Runner) Down(steps int) ([]Migration, error) {
+	known := make(map[int]Migration, len(r.Migrations))
+	for _, migration := range r.Migrations {
+		known[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	err := r.locked(func(conn *gorm.DB) error {
+		var rows []schemaMigration
+		if err := conn.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			migration, ok := known[row.Version]
+			if !ok {
+				return fmt.Errorf("migration %d_%s is not known to this binary and cannot be reverted", row.Version, row.Name)
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := exec(tx, migration.Down); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, "version = ?", migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists the known migrations and any applied ones the binary does
+// not know, ordered by version.
+func (r *Runner) Status() ([]Status, error) {
+	rows, err := r.applied(r.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	statuses := make([]Status, 0, len(r.Migrations))
+	for _, migration := range r.Migrations {
+		status := Status{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			status.Changed = row.Checksum != migration.Checksum()
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range applied {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: row.Version, Name: row.Name},
+			AppliedAt: &appliedAt,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// RequireCurrent fails with ErrSchemaBehind when migrations are pending. A
+// database that is ahead of the binary, as during a rollback, is accepted.
+func (r *Runner) RequireCurrent() error {
+	pending, err := r.pending(r.DB)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending migration(s) up to %d_%s, run \"migrate up\" first",
+			ErrSchemaBehind, len(pending), pending[len(pending)-1].Version, pending[len(pending)-1].Name)
+	}
+	return nil
+}
+
+// CheckSchema refuses to start the server against an outdated schema.
+func CheckSchema(db *gorm.DB) error {
+	runner, err := NewRunner(db)
+	if err != nil {
+		return err
+	}
+	return runner.RequireCurrent()
+}
+
+// Create writes empty up and down files for a new migration to dir, numbered
+// after the highest version found there, and returns their paths.
+func Create(dir, name string) (string, string, error) {
+	name = strings.Trim(nameInvalid.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("migration name must contain letters or digits")
+	}
+
+	existing, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	version := 1
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%04d_%s", version, name))
+	up, down := base+".up.sql", base+".down.sql"
+	if err := os.WriteFile(up, []byte("-- Write the schema change here.\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(down, []byte("-- Write the statements that undo the up migration here.\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	return up, down, nil
+}
+
+// locked runs fn on a single connection holding the migration lock, creating
+// the schema_migrations table first if needed.
+func (r *Runner) locked(fn func(conn *gorm.DB) error) error {
+	return r.DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockID).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", lockID)
+
+		err := conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name varchar(255) NOT NULL,
+			checksum varchar(64) NOT NULL,
+			applied_at timestamptz NOT NULL
+		)`).Error
+		if err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func (r *Runner) pending(db *gorm.DB) ([]Migration, error) {
+	rows, err := r.applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range r.Migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// applied returns the recorded migrations, or none when nothing has been
+// migrated yet.
+func (r *Runner) applied(db *gorm.DB) ([]schemaMigration, error) {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return nil, nil
+	}
+
+	var rows []schemaMigration
+	err := db.Order("version").Find(&rows).Error
+	return rows, err
+}
+
+// exec runs a script that may hold several statements. Scripts with nothing
+// but comments, such as irreversible down migrations, are skipped.
+func exec(tx *gorm.DB, script string) error {
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return tx.Exec(script).Error
+		}
+	}
+	return nil
+}
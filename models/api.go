@@ -14,57 +14,476 @@ type UserLoginRequest struct {
 }
 
 type AuthResponse struct {
-	Token     string `json:"token"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	Token         string `json:"token"`
+	RefreshToken  string `json:"refresh_token"`
+	ExpiresIn     int64  `json:"expires_in"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Role          string `json:"role"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
 }
 
 type MovieRequest struct {
-	Title    string  `json:"title" binding:"required"`
-	Director string  `json:"director" binding:"required"`
-	Year     int     `json:"year" binding:"required"`
-	Plot     string  `json:"plot"`
-	Genre    string  `json:"genre"`
-	Rating   float32 `json:"rating"`
+	Title    string   `json:"title" binding:"required"`
+	Director string   `json:"director" binding:"required"`
+	Year     int      `json:"year" binding:"required"`
+	Plot     string   `json:"plot"`
+	Genres   []string `json:"genres" binding:"max=5,dive,max=50"`
+}
+
+// ImportQuery controls a bulk import. Each Map entry is "header:field" and
+// maps a CSV column to a MovieRequest field; columns named after a field map
+// to it by default.
+type ImportQuery struct {
+	DryRun bool     `form:"dry_run"`
+	Map    []string `form:"map"`
+}
+
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportResult struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
 }
 
 type MovieCreateResponse struct {
-	Title     string  `json:"title"`
-	Director  string  `json:"director"`
-	Year      int     `json:"year"`
-	Plot      string  `json:"plot"`
-	Genre     string  `json:"genre"`
-	Rating    float32 `json:"rating"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	Title     string          `json:"title"`
+	Director  string          `json:"director"`
+	Year      int             `json:"year"`
+	Plot      string          `json:"plot"`
+	Genre     string          `json:"genre"`
+	Genres    []GenreResponse `json:"genres"`
+	Rating    float32         `json:"rating"`
+	CreatedAt string          `json:"created_at"`
+	UpdatedAt string          `json:"updated_at"`
 }
 
 type MovieResponse struct {
-	ID        uint      `json:"id"`
-	Title     string    `json:"title"`
-	Director  string    `json:"director"`
-	Year      int       `json:"year"`
-	Plot      string    `json:"plot"`
-	Genre     string    `json:"genre"`
-	Rating    float32   `json:"rating"`
-	UserID    uint      `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            uint             `json:"id"`
+	Title         string           `json:"title"`
+	Director      string           `json:"director"`
+	Year          int              `json:"year"`
+	Plot          string           `json:"plot"`
+	Genre         string           `json:"genre"`
+	Genres        []GenreResponse  `json:"genres"`
+	Credits       []CreditResponse `json:"credits,omitempty"`
+	Rating        float32          `json:"rating"`
+	AverageRating float64          `json:"average_rating"`
+	VoteCount     int              `json:"vote_count"`
+	Version       int              `json:"version"`
+	Poster        *ImageResponse   `json:"poster,omitempty"`
+	Backdrop      *ImageResponse   `json:"backdrop,omitempty"`
+	UserID        uint             `json:"user_id"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+type ImageResponse struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// Thumbnails maps a size such as "w185" to the URL of a JPEG scaled to
+	// that width.
+	Thumbnails map[string]string `json:"thumbnails"`
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+type PageQuery struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type MovieListQuery struct {
+	PageQuery
+	UserID    uint    `form:"-"`
+	Genre     string  `form:"genre"`
+	Director  string  `form:"director"`
+	MinYear   int     `form:"min_year" binding:"omitempty,min=0"`
+	MaxYear   int     `form:"max_year" binding:"omitempty,min=0"`
+	MinRating float32 `form:"min_rating" binding:"omitempty,min=0"`
+	MaxRating float32 `form:"max_rating" binding:"omitempty,min=0"`
+	Sort      string  `form:"sort"`
+}
+
+// MovieExportQuery takes the listing filters and sort; page and limit are
+// ignored since every matching movie is exported.
+type MovieExportQuery struct {
+	MovieListQuery
+	Format string `form:"format" binding:"omitempty,oneof=csv ndjson xlsx"`
+}
+
+// MovieExport is a movie as written by an export. Its fields match
+// MovieRequest so that exported files can be imported again.
+type MovieExport struct {
+	ID            uint      `json:"id"`
+	Title         string    `json:"title"`
+	Director      string    `json:"director"`
+	Year          int       `json:"year"`
+	Plot          string    `json:"plot"`
+	Genres        []string  `json:"genres"`
+	Rating        float32   `json:"rating"`
+	AverageRating float64   `json:"average_rating"`
+	VoteCount     int       `json:"vote_count"`
+	UserID        uint      `json:"user_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type MovieSearchQuery struct {
+	PageQuery
+	Q string `form:"q" binding:"required"`
+}
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+	NextPage   *int  `json:"next_page"`
+	PrevPage   *int  `json:"prev_page"`
+}
+
 type Movies struct {
-	Movies []MovieResponse `json:"movies"`
+	Movies     []MovieResponse `json:"movies"`
+	Pagination Pagination      `json:"pagination"`
 }
 
 type UserRegisterResponse struct {
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Role          string `json:"role"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type MovieSearchResult struct {
+	Movie   MovieResponse `json:"movie"`
+	Rank    float32       `json:"rank"`
+	Snippet string        `json:"snippet"`
+}
+
+type MovieSearchResults struct {
+	Results    []MovieSearchResult `json:"results"`
+	Pagination Pagination          `json:"pagination"`
+}
+
+type GenreCount struct {
+	Genre string `json:"genre"`
+	Count int64  `json:"count"`
+}
+
+type UserMovieStats struct {
+	MovieCount    int64        `json:"movie_count"`
+	AverageRating float64      `json:"average_rating"`
+	TopGenres     []GenreCount `json:"top_genres"`
+}
+
+type UserMovies struct {
+	Username   string          `json:"username"`
+	Stats      UserMovieStats  `json:"stats"`
+	Movies     []MovieResponse `json:"movies"`
+	Pagination Pagination      `json:"pagination"`
+}
+
+type UserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin"`
+}
+
+type UserResponse struct {
+	ID            uint   `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Role          string `json:"role"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type ReviewRequest struct {
+	Score int    `json:"score" binding:"required,min=1,max=10"`
+	Text  string `json:"text" binding:"max=10000"`
+}
+
+type ReviewResponse struct {
+	ID        uint      `json:"id"`
+	MovieID   uint      `json:"movie_id"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username"`
+	Score     int       `json:"score"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Reviews struct {
+	Reviews    []ReviewResponse `json:"reviews"`
+	Pagination Pagination       `json:"pagination"`
+}
+
+type GenreResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type GenreWithCountResponse struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+	MovieCount int64  `json:"movie_count"`
+}
+
+type Genres struct {
+	Genres []GenreWithCountResponse `json:"genres"`
+}
+
+type MovieDetailQuery struct {
+	Include string `form:"include"`
+}
+
+type PersonListQuery struct {
+	PageQuery
+	Q string `form:"q"`
+}
+
+type PersonRequest struct {
+	Name      string `json:"name" binding:"required,max=255"`
+	Bio       string `json:"bio"`
+	BirthDate string `json:"birth_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type PersonResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio"`
+	BirthDate string    `json:"birth_date,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type People struct {
+	People     []PersonResponse `json:"people"`
+	Pagination Pagination       `json:"pagination"`
+}
+
+type CreditRequest struct {
+	PersonID  uint   `json:"person_id" binding:"required"`
+	Role      string `json:"role" binding:"required,oneof=director actor writer composer"`
+	Character string `json:"character" binding:"max=255"`
+	Position  int    `json:"position" binding:"min=0"`
+}
+
+type CreditResponse struct {
+	ID         uint   `json:"id"`
+	PersonID   uint   `json:"person_id"`
+	PersonName string `json:"person_name"`
+	Role       string `json:"role"`
+	Character  string `json:"character,omitempty"`
+	Position   int    `json:"position"`
+}
+
+type Credits struct {
+	Credits []CreditResponse `json:"credits"`
+}
+
+type FilmographyEntry struct {
+	CreditID  uint   `json:"credit_id"`
+	MovieID   uint   `json:"movie_id"`
+	Title     string `json:"title"`
+	Year      int    `json:"year"`
+	Role      string `json:"role"`
+	Character string `json:"character,omitempty"`
+}
+
+type Filmography struct {
+	Person  PersonResponse     `json:"person"`
+	Credits []FilmographyEntry `json:"credits"`
+}
+
+type WatchlistRequest struct {
+	MovieID  uint `json:"movie_id" binding:"required"`
+	Position int  `json:"position" binding:"min=0"`
+}
+
+type WatchlistPositionRequest struct {
+	Position int `json:"position" binding:"required,min=1"`
+}
+
+type WatchlistEntryResponse struct {
+	Position int           `json:"position"`
+	AddedAt  time.Time     `json:"added_at"`
+	Movie    MovieResponse `json:"movie"`
+}
+
+type Watchlist struct {
+	Entries    []WatchlistEntryResponse `json:"entries"`
+	Pagination Pagination               `json:"pagination"`
+}
+
+type DiaryEntryRequest struct {
+	WatchedOn string `json:"watched_on" binding:"omitempty,datetime=2006-01-02"`
+	Rewatch   bool   `json:"rewatch"`
+	Notes     string `json:"notes" binding:"max=10000"`
+}
+
+type DiaryCreateRequest struct {
+	MovieID uint `json:"movie_id" binding:"required"`
+	DiaryEntryRequest
+}
+
+type DiaryEntryResponse struct {
+	ID        uint          `json:"id"`
+	WatchedOn string        `json:"watched_on"`
+	Rewatch   bool          `json:"rewatch"`
+	Notes     string        `json:"notes"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Movie     MovieResponse `json:"movie"`
+}
+
+type Diary struct {
+	Entries    []DiaryEntryResponse `json:"entries"`
+	Pagination Pagination           `json:"pagination"`
+}
+
+type ListRequest struct {
+	Title       string `json:"title" binding:"required,max=255"`
+	Description string `json:"description" binding:"max=10000"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
+}
+
+type ListViewQuery struct {
+	Token string `form:"token"`
+}
+
+type ListItemsQuery struct {
+	PageQuery
+	ListViewQuery
+}
+
+type ListCollaboratorResponse struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type ListResponse struct {
+	ID            uint                       `json:"id"`
+	Title         string                     `json:"title"`
+	Description   string                     `json:"description"`
+	Visibility    string                     `json:"visibility"`
+	OwnerID       uint                       `json:"owner_id"`
+	OwnerUsername string                     `json:"owner_username"`
+	ItemCount     int64                      `json:"item_count"`
+	ShareToken    string                     `json:"share_token,omitempty"`
+	Collaborators []ListCollaboratorResponse `json:"collaborators,omitempty"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	UpdatedAt     time.Time                  `json:"updated_at"`
+}
+
+type Lists struct {
+	Lists      []ListResponse `json:"lists"`
+	Pagination Pagination     `json:"pagination"`
+}
+
+type ListItemRequest struct {
+	MovieID  uint   `json:"movie_id" binding:"required"`
+	Note     string `json:"note" binding:"max=10000"`
+	Position int    `json:"position" binding:"min=0"`
+}
+
+type ListItemUpdateRequest struct {
+	Note     string `json:"note" binding:"max=10000"`
+	Position int    `json:"position" binding:"min=0"`
+}
+
+type ListItemResponse struct {
+	Position  int           `json:"position"`
+	Note      string        `json:"note"`
+	AddedByID uint          `json:"added_by_id"`
+	AddedAt   time.Time     `json:"added_at"`
+	Movie     MovieResponse `json:"movie"`
+}
+
+type ListItems struct {
+	Items      []ListItemResponse `json:"items"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+type CollaboratorRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type RevisionResponse struct {
+	Revision       int           `json:"revision"`
+	Action         string        `json:"action"`
+	EditorID       uint          `json:"editor_id"`
+	EditorUsername string        `json:"editor_username"`
+	RevertedFrom   *int          `json:"reverted_from,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	Snapshot       MovieSnapshot `json:"snapshot"`
+}
+
+type Revisions struct {
+	Revisions  []RevisionResponse `json:"revisions"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+type RevisionDiffQuery struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
+}
+
+type RevisionDiff struct {
+	MovieID uint          `json:"movie_id"`
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+type TrashEntry struct {
+	Movie     MovieResponse `json:"movie"`
+	DeletedAt time.Time     `json:"deleted_at"`
+	// PurgeAt is omitted when the trash is never purged.
+	PurgeAt *time.Time `json:"purge_at,omitempty"`
+}
+
+type Trash struct {
+	Movies     []TrashEntry `json:"movies"`
+	Pagination Pagination   `json:"pagination"`
 }
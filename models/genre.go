@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Genre is a canonical genre. Movies reference genres through the
+// movie_genres join table.
+type Genre struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	Slug      string    `gorm:"size:100;not null;uniqueIndex" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GenreWithCount struct {
+	Genre
+	MovieCount int64
+}
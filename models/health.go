@@ -0,0 +1,20 @@
+package models
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusFailing  = "failing"
+	HealthStatusDraining = "draining"
+)
+
+// HealthReport is the body of the probe endpoints. Checks holds the outcome
+// of each dependency check, by name, when any were run. The probes are
+// public, so failures are only detailed in the log.
+type HealthReport struct {
+	Status string                       `json:"status" example:"ok"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+type HealthCheckResult struct {
+	Status    string  `json:"status" example:"ok"`
+	LatencyMS float64 `json:"latency_ms" example:"1.25"`
+}
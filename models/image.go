@@ -0,0 +1,53 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	ImagePoster   = "poster"
+	ImageBackdrop = "backdrop"
+
+	// ImageOriginal names the uploaded file in an ImageSet; the other sizes
+	// are thumbnails named after their width, such as "w185".
+	ImageOriginal = "original"
+)
+
+// ImageSet is an uploaded movie image and its thumbnails, stored as JSON in
+// a column of the movie. Keys and URLs are indexed by size name. The zero
+// value means there is no image and is stored as NULL.
+type ImageSet struct {
+	ContentType string            `json:"content_type"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	Keys        map[string]string `json:"keys"`
+	URLs        map[string]string `json:"urls"`
+}
+
+// Empty reports whether no image has been uploaded.
+func (s ImageSet) Empty() bool {
+	return len(s.Keys) == 0
+}
+
+func (s ImageSet) Value() (driver.Value, error) {
+	if s.Empty() {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *ImageSet) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*s = ImageSet{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return errors.New("unsupported image set value")
+	}
+}
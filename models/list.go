@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+const (
+	ListPrivate  = "private"
+	ListUnlisted = "unlisted"
+	ListPublic   = "public"
+)
+
+const (
+	ListEditor = "editor"
+	ListViewer = "viewer"
+)
+
+// List is a user-curated, ordered collection of movies. Private lists are
+// visible to the owner and collaborators only, unlisted lists also to anyone
+// with the share token, and public lists to everyone.
+type List struct {
+	ID          uint   `gorm:"primarykey" json:"id"`
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	Title       string `gorm:"size:255;not null" json:"title"`
+	Description string `gorm:"type:text" json:"description"`
+	Visibility  string `gorm:"size:20;not null;default:private;index" json:"visibility"`
+	ShareToken  string `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	// ItemCount is filled in by queries, it is not a column.
+	ItemCount int64     `gorm:"->;-:migration" json:"item_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ModifiedByID and ModifiedVia record who last changed the list and
+	// which grant (owner, editor, moderator or admin) allowed it.
+	ModifiedByID  uint               `json:"modified_by_id"`
+	ModifiedVia   string             `gorm:"size:20" json:"modified_via"`
+	User          User               `json:"-"`
+	Collaborators []ListCollaborator `json:"collaborators"`
+}
+
+// ListItem is a movie on a list. Position orders the list, starting at 1.
+type ListItem struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ListID    uint      `gorm:"not null;uniqueIndex:idx_list_items_list_movie" json:"list_id"`
+	MovieID   uint      `gorm:"not null;uniqueIndex:idx_list_items_list_movie;index" json:"movie_id"`
+	Position  int       `gorm:"not null" json:"position"`
+	Note      string    `gorm:"type:text" json:"note"`
+	AddedByID uint      `json:"added_by_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Movie     Movie     `json:"movie"`
+}
+
+// ListCollaborator grants another user editor or viewer rights on a list.
+type ListCollaborator struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ListID    uint      `gorm:"not null;uniqueIndex:idx_list_collaborators_list_user" json:"list_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_list_collaborators_list_user;index" json:"user_id"`
+	Role      string    `gorm:"size:20;not null" json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	User      User      `json:"-"`
+}
@@ -8,13 +8,35 @@ import (
 
 type Movie struct {
 	gorm.Model
-	Title     string    `gorm:"size:255;not null" json:"title" binding:"required"`
-	Director  string    `gorm:"size:255;not null" json:"director" binding:"required"`
-	Year      int       `json:"year" binding:"required"`
-	Plot      string    `gorm:"type:text" json:"plot"`
-	Genre     string    `gorm:"size:100" json:"genre"`
-	Rating    float32   `json:"rating"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UserID    uint      `json:"user_id"`
+	Title    string `gorm:"size:255;not null" json:"title" binding:"required"`
+	Director string `gorm:"size:255;not null" json:"director" binding:"required"`
+	Year     int    `json:"year" binding:"required"`
+	Plot     string `gorm:"type:text" json:"plot"`
+	// Genre holds the canonical names of Genres joined by ", " so that
+	// full-text search can match them.
+	Genre  string  `gorm:"size:100" json:"genre"`
+	Genres []Genre `gorm:"many2many:movie_genres;" json:"genres"`
+	Rating float32 `json:"rating"`
+	// Version is incremented on every change to the movie or its credits,
+	// but not on reviews. It backs the movie's ETag.
+	Version int `gorm:"not null;default:1" json:"version"`
+	// Poster and Backdrop are maintained by ImageService.
+	Poster   ImageSet `gorm:"type:jsonb" json:"poster"`
+	Backdrop ImageSet `gorm:"type:jsonb" json:"backdrop"`
+	// AverageRating and VoteCount are computed from reviews.
+	AverageRating float64   `gorm:"not null;default:0" json:"average_rating"`
+	VoteCount     int       `gorm:"not null;default:0" json:"vote_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	UserID        uint      `json:"user_id"`
+	// ModifiedByID and ModifiedVia record who last updated or deleted the
+	// movie and which grant (owner, moderator or admin) allowed it.
+	ModifiedByID uint   `json:"modified_by_id"`
+	ModifiedVia  string `gorm:"size:20" json:"modified_via"`
+}
+
+type MovieSearchHit struct {
+	Movie
+	Rank    float32
+	Snippet string
 }
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	CreditDirector = "director"
+	CreditActor    = "actor"
+	CreditWriter   = "writer"
+	CreditComposer = "composer"
+)
+
+// Person is anyone credited on a movie: directors, cast and crew.
+type Person struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Name        string     `gorm:"size:255;not null;index" json:"name"`
+	Bio         string     `gorm:"type:text" json:"bio"`
+	BirthDate   *time.Time `gorm:"type:date" json:"birth_date"`
+	CreatedByID uint       `json:"created_by_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Credit links a person to a movie in a role. Character is only used for
+// actors; Position orders the credits of a movie.
+type Credit struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	MovieID   uint      `gorm:"not null;index" json:"movie_id"`
+	PersonID  uint      `gorm:"not null;index" json:"person_id"`
+	Role      string    `gorm:"size:20;not null" json:"role"`
+	Character string    `gorm:"size:255" json:"character"`
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	Person    Person    `json:"person"`
+	Movie     Movie     `json:"-"`
+}
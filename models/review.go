@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Review is one user's score and optional text for a movie. Each user can
+// review a movie once.
+type Review struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	MovieID   uint      `gorm:"not null;uniqueIndex:idx_reviews_movie_user" json:"movie_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_reviews_movie_user;index" json:"user_id"`
+	Score     int       `gorm:"not null" json:"score"`
+	Text      string    `gorm:"type:text" json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `json:"-"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRevert  = "revert"
+	RevisionRestore = "restore"
+)
+
+// MovieRevision is an immutable snapshot of a movie taken after each create,
+// update, revert, delete and restore. Revision numbers start at 1 for each movie.
+type MovieRevision struct {
+	ID       uint          `gorm:"primarykey" json:"id"`
+	MovieID  uint          `gorm:"not null;uniqueIndex:idx_movie_revisions_movie_revision" json:"movie_id"`
+	Revision int           `gorm:"not null;uniqueIndex:idx_movie_revisions_movie_revision" json:"revision"`
+	Action   string        `gorm:"size:20;not null" json:"action"`
+	Snapshot MovieSnapshot `gorm:"type:jsonb;not null" json:"snapshot"`
+	EditorID uint          `gorm:"not null" json:"editor_id"`
+	// RevertedFrom is the revision restored by a revert.
+	RevertedFrom *int      `json:"reverted_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	Editor       User      `gorm:"foreignKey:EditorID" json:"-"`
+}
+
+// MovieSnapshot holds the state of a movie at one revision. Review
+// aggregates change without a revision, so they are not part of it.
+type MovieSnapshot struct {
+	Title        string    `json:"title"`
+	Director     string    `json:"director"`
+	Year         int       `json:"year"`
+	Plot         string    `json:"plot"`
+	Genres       []string  `json:"genres"`
+	Rating       float32   `json:"rating"`
+	UserID       uint      `json:"user_id"`
+	ModifiedByID uint      `json:"modified_by_id"`
+	ModifiedVia  string    `json:"modified_via"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (s MovieSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *MovieSnapshot) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return errors.New("unsupported movie snapshot value")
+	}
+}
+
+// FieldChange is one field that differs between two revisions.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// RefreshToken is an opaque, single-use token stored as a SHA-256 hash.
+// Every token issued from one login shares a FamilyID, so reuse of a rotated
+// token can revoke the whole session.
+type RefreshToken struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	FamilyID  string     `gorm:"size:64;not null;index" json:"family_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+// RevokedToken is a denylisted access token, kept until the token would have
+// expired anyway.
+type RevokedToken struct {
+	TokenID   string    `gorm:"primarykey;size:64" json:"token_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccessClaims are the claims of a validated access token.
+type AccessClaims struct {
+	UserID        uint
+	Role          string
+	SessionID     string
+	TokenID       string
+	IssuedAt      time.Time
+	ExpiresAt     time.Time
+	EmailVerified bool
+}
+
+// PasswordResetToken is a single-use, expiring token stored as a SHA-256
+// hash.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
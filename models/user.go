@@ -6,11 +6,29 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type User struct {
 	gorm.Model
-	Username  string    `gorm:"size:255;not null;unique" json:"username"`
-	Password  string    `gorm:"size:255;not null" json:"-"`
-	Email     string    `gorm:"size:255;not null;unique" json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Username string `gorm:"size:255;not null;unique" json:"username"`
+	Password string `gorm:"size:255;not null" json:"-"`
+	Email    string `gorm:"size:255;not null;unique" json:"email"`
+	Role     string `gorm:"size:20;not null;default:user" json:"role"`
+	// TokensInvalidBefore rejects every access token issued before it.
+	TokensInvalidBefore *time.Time `json:"-"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	// VerificationSentAt throttles re-sending of verification emails.
+	VerificationSentAt *time.Time `json:"-"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// Actor is the authenticated user performing a request.
+type Actor struct {
+	UserID uint
+	Role   string
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// WatchlistEntry is a movie a user wants to watch. Position orders the
+// user's watchlist, starting at 1.
+type WatchlistEntry struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_watchlist_user_movie" json:"user_id"`
+	MovieID   uint      `gorm:"not null;uniqueIndex:idx_watchlist_user_movie;index" json:"movie_id"`
+	Position  int       `gorm:"not null" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	Movie     Movie     `json:"movie"`
+}
+
+// DiaryEntry logs one viewing of a movie by a user. A user can log the same
+// movie any number of times.
+type DiaryEntry struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	MovieID   uint      `gorm:"not null;index" json:"movie_id"`
+	WatchedOn time.Time `gorm:"type:date;not null" json:"watched_on"`
+	Rewatch   bool      `gorm:"not null;default:false" json:"rewatch"`
+	Notes     string    `gorm:"type:text" json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Movie     Movie     `json:"movie"`
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DiaryRepository struct {
+	DB *gorm.DB
+}
+
+func NewDiaryRepository(db *gorm.DB) *DiaryRepository {
+	return &DiaryRepository{DB: db}
+}
+
+// List returns a page of the user's diary, most recent viewing first.
+// Entries for deleted movies are skipped.
+func (r *DiaryRepository) List(userID uint, page models.PageQuery) ([]models.DiaryEntry, int64, error) {
+	db := r.DB.Model(&models.DiaryEntry{}).
+		Where("user_id = ? AND movie_id IN (SELECT id FROM movies WHERE deleted_at IS NULL)", userID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.DiaryEntry
+	result := db.Preload("Movie").
+		Preload("Movie.Genres", orderGenres).
+		Order("watched_on DESC, id DESC").
+		Limit(page.Limit).
+		Offset((page.Page - 1) * page.Limit).
+		Find(&entries)
+	return entries, total, result.Error
+}
+
+func (r *DiaryRepository) GetByID(userID, id uint) (models.DiaryEntry, error) {
+	var entry models.DiaryEntry
+	result := r.DB.Preload("Movie").
+		Preload("Movie.Genres", orderGenres).
+		Where("user_id = ? AND movie_id IN (SELECT id FROM movies WHERE deleted_at IS NULL)", userID).
+		First(&entry, id)
+	return entry, result.Error
+}
+
+func (r *DiaryRepository) Create(entry *models.DiaryEntry) error {
+	return r.DB.Omit(clause.Associations).Create(entry).Error
+}
+
+func (r *DiaryRepository) Update(entry *models.DiaryEntry) error {
+	return r.DB.Model(entry).Select("watched_on", "rewatch", "notes").Updates(entry).Error
+}
+
+func (r *DiaryRepository) Delete(id uint) error {
+	return r.DB.Delete(&models.DiaryEntry{}, id).Error
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GenreRepository struct {
+	DB *gorm.DB
+}
+
+func NewGenreRepository(db *gorm.DB) *GenreRepository {
+	return &GenreRepository{DB: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *GenreRepository) WithTx(tx *gorm.DB) *GenreRepository {
+	return &GenreRepository{DB: tx}
+}
+
+// ListWithCounts returns every genre with the number of movies using it.
+func (r *GenreRepository) ListWithCounts() ([]models.GenreWithCount, error) {
+	var genres []models.GenreWithCount
+	result := r.DB.Table("genres").
+		Select("genres.id, genres.name, genres.slug, genres.created_at, COUNT(movies.id) AS movie_count").
+		Joins("LEFT JOIN movie_genres ON movie_genres.genre_id = genres.id").
+		Joins("LEFT JOIN movies ON movies.id = movie_genres.movie_id AND movies.deleted_at IS NULL").
+		Group("genres.id").
+		Order("movie_count DESC, genres.name ASC").
+		Scan(&genres)
+	return genres, result.Error
+}
+
+// FindOrCreate returns the stored genres for the given slugs, inserting the
+// ones that do not exist yet.
+func (r *GenreRepository) FindOrCreate(genres []models.Genre) ([]models.Genre, error) {
+	if len(genres) == 0 {
+		return []models.Genre{}, nil
+	}
+
+	// Insert a copy: with DO NOTHING the returned IDs do not line up with
+	// the input when some slugs already exist.
+	rows := append([]models.Genre(nil), genres...)
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "slug"}},
+		DoNothing: true,
+	}).Create(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, len(genres))
+	for i, genre := range genres {
+		slugs[i] = genre.Slug
+	}
+
+	var stored []models.Genre
+	result := r.DB.Where("slug IN ?", slugs).Order("name ASC").Find(&stored)
+	return stored, result.Error
+}
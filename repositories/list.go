@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// listItemCount selects a list's columns along with the number of items
+// whose movie has not been deleted.
+const listItemCount = `lists.*, (
+	SELECT COUNT(*) FROM list_items JOIN movies ON movies.id = list_items.movie_id
+	WHERE list_items.list_id = lists.id AND movies.deleted_at IS NULL
+) AS item_count`
+
+type ListRepository struct {
+	DB *gorm.DB
+}
+
+func NewListRepository(db *gorm.DB) *ListRepository {
+	return &ListRepository{DB: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *ListRepository) WithTx(tx *gorm.DB) *ListRepository {
+	return &ListRepository{DB: tx}
+}
+
+// ListPublic returns a page of public lists, most recently updated first.
+func (r *ListRepository) ListPublic(page models.PageQuery) ([]models.List, int64, error) {
+	return r.page(r.DB.Where("visibility = ?", models.ListPublic), page)
+}
+
+// ListForUser returns a page of the lists a user owns or collaborates on.
+func (r *ListRepository) ListForUser(userID uint, page models.PageQuery) ([]models.List, int64, error) {
+	return r.page(r.DB.Where(
+		"user_id = ? OR id IN (SELECT list_id FROM list_collaborators WHERE user_id = ?)",
+		userID, userID,
+	), page)
+}
+
+func (r *ListRepository) page(db *gorm.DB, page models.PageQuery) ([]models.List, int64, error) {
+	db = db.Model(&models.List{}).Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var lists []models.List
+	result := db.Select(listItemCount).
+		Preload("User").
+		Order("updated_at DESC, id DESC").
+		Limit(page.Limit).
+		Offset((page.Page - 1) * page.Limit).
+		Find(&lists)
+	return lists, total, result.Error
+}
+
+func (r *ListRepository) GetByID(id uint) (models.List, error) {
+	var list models.List
+	result := r.DB.Select(listItemCount).
+		Preload("User").
+		Preload("Collaborators", func(db *gorm.DB) *gorm.DB {
+			return db.Order("list_collaborators.id ASC")
+		}).
+		Preload("Collaborators.User").
+		First(&list, id)
+	return list, result.Error
+}
+
+// GetForUpdate loads a list and locks its row until the transaction ends.
+func (r *ListRepository) GetForUpdate(id uint) (models.List, error) {
+	var list models.List
+	result := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).First(&list, id)
+	return list, result.Error
+}
+
+func (r *ListRepository) Create(list *models.List) error {
+	return r.DB.Omit(clause.Associations).Create(list).Error
+}
+
+func (r *ListRepository) Update(list *models.List) error {
+	return r.DB.Model(list).
+		Select("title", "description", "visibility", "modified_by_id", "modified_via", "updated_at").
+		Updates(list).Error
+}
+
+func (r *ListRepository) UpdateShareToken(id uint, token string) error {
+	return r.DB.Model(&models.List{}).Where("id = ?", id).Update("share_token", token).Error
+}
+
+// Touch records a change to the items of a list.
+func (r *ListRepository) Touch(list *models.List) error {
+	return r.DB.Model(list).
+		Select("modified_by_id", "modified_via", "updated_at").
+		Updates(list).Error
+}
+
+// Delete removes a list together with its items and collaborators.
+func (r *ListRepository) Delete(id uint) error {
+	if err := r.DB.Where("list_id = ?", id).Delete(&models.ListItem{}).Error; err != nil {
+		return err
+	}
+	if err := r.DB.Where("list_id = ?", id).Delete(&models.ListCollaborator{}).Error; err != nil {
+		return err
+	}
+	return r.DB.Delete(&models.List{}, id).Error
+}
+
+// Items returns a page of a list's items in order. Items for deleted movies
+// are skipped.
+func (r *ListRepository) Items(listID uint, page models.PageQuery) ([]models.ListItem, int64, error) {
+	db := r.DB.Model(&models.ListItem{}).
+		Where("list_id = ? AND movie_id IN (SELECT id FROM movies WHERE deleted_at IS NULL)", listID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []models.ListItem
+	result := db.Preload("Movie").
+		Preload("Movie.Genres", orderGenres).
+		Order("position ASC, id ASC").
+		Limit(page.Limit).
+		Offset((page.Page - 1) * page.Limit).
+		Find(&items)
+	return items, total, result.Error
+}
+
+// AllItems returns every item of a list in order. Items for movies in the
+// trash are included so that renumbering keeps their slots for a restore.
+// Callers lock the list first.
+func (r *ListRepository) AllItems(listID uint) ([]models.ListItem, error) {
+	var items []models.ListItem
+	result := r.DB.
+		Where("list_id = ?", listID).
+		Order("position ASC, id ASC").
+		Find(&items)
+	return items, result.Error
+}
+
+// HasItem reports whether the movie is on the list.
+func (r *ListRepository) HasItem(listID, movieID uint) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.ListItem{}).
+		Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+func (r *ListRepository) CreateItem(item *models.ListItem) error {
+	return r.DB.Omit(clause.Associations).Create(item).Error
+}
+
+func (r *ListRepository) UpdateItemNote(item *models.ListItem) error {
+	return r.DB.Model(item).Select("note", "updated_at").Updates(item).Error
+}
+
+func (r *ListRepository) UpdateItemPosition(id uint, position int) error {
+	return r.DB.Model(&models.ListItem{}).Where("id = ?", id).Update("position", position).Error
+}
+
+func (r *ListRepository) DeleteItem(id uint) error {
+	return r.DB.Delete(&models.ListItem{}, id).Error
+}
+
+func (r *ListRepository) GetCollaborator(listID, userID uint) (models.ListCollaborator, error) {
+	var collaborator models.ListCollaborator
+	result := r.DB.Where("list_id = ? AND user_id = ?", listID, userID).First(&collaborator)
+	return collaborator, result.Error
+}
+
+// SaveCollaborator adds a collaborator or changes the role of an existing
+// one.
+func (r *ListRepository) SaveCollaborator(collaborator *models.ListCollaborator) error {
+	return r.DB.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "list_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(collaborator).Error
+}
+
+// DeleteCollaborator removes a collaborator and reports whether there was
+// one.
+func (r *ListRepository) DeleteCollaborator(listID, userID uint) (bool, error) {
+	result := r.DB.Where("list_id = ? AND user_id = ?", listID, userID).Delete(&models.ListCollaborator{})
+	return result.RowsAffected > 0, result.Error
+}
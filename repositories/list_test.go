@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// captureQueries records the SQL of every query run on db.
+func captureQueries(t *testing.T, db *gorm.DB) *[]string {
+	t.Helper()
+	var queries []string
+	err := db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		queries = append(queries, tx.Statement.SQL.String())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &queries
+}
+
+func TestAllItemsIncludesTrashedMovies(t *testing.T) {
+	db := dryRunDB(t)
+	queries := captureQueries(t, db)
+
+	if _, err := NewListRepository(db).AllItems(3); err != nil {
+		t.Fatal(err)
+	}
+	if len(*queries) != 1 {
+		t.Fatalf("AllItems ran %d queries, want 1", len(*queries))
+	}
+	if sql := (*queries)[0]; strings.Contains(sql, "deleted_at") {
+		t.Errorf("AllItems SQL = %q, want items of trashed movies included", sql)
+	}
+}
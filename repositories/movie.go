@@ -2,9 +2,11 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"github.com/dostonshernazarov/movies-app/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MovieRepository struct {
@@ -15,15 +17,137 @@ func NewMovieRepository(db *gorm.DB) *MovieRepository {
 	return &MovieRepository{DB: db}
 }
 
-func (r *MovieRepository) GetAll() ([]models.Movie, error) {
+// WithTx returns a repository bound to the given transaction.
+func (r *MovieRepository) WithTx(tx *gorm.DB) *MovieRepository {
+	return &MovieRepository{DB: tx}
+}
+
+func (r *MovieRepository) List(query models.MovieListQuery, order string) ([]models.Movie, int64, error) {
+	db := applyMovieFilters(r.DB.Model(&models.Movie{}), query).Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	var movies []models.Movie
-	result := r.DB.Select("id, title, director, year, plot, genre, rating, user_id, created_at, updated_at").Find(&movies)
-	return movies, result.Error
+	result := db.Preload("Genres", orderGenres).
+		Order(order).
+		Limit(query.Limit).
+		Offset((query.Page - 1) * query.Limit).
+		Find(&movies)
+	return movies, total, result.Error
+}
+
+// streamBatchSize is the number of movies Stream reads before loading their
+// genres.
+const streamBatchSize = 500
+
+// Stream calls fn for each movie matching the query in the given order. Rows
+// are read from the result cursor in batches instead of being loaded into
+// memory, and the Genres of each batch are loaded with one query. An error
+// from fn stops the iteration.
+func (r *MovieRepository) Stream(query models.MovieListQuery, order string, fn func(models.Movie) error) error {
+	rows, err := applyMovieFilters(r.DB.Model(&models.Movie{}), query).Order(order).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]models.Movie, 0, streamBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(batch))
+		for i, movie := range batch {
+			ids[i] = movie.ID
+		}
+		genres, err := r.genresByMovie(ids)
+		if err != nil {
+			return err
+		}
+		for _, movie := range batch {
+			movie.Genres = genres[movie.ID]
+			if err := fn(movie); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		var movie models.Movie
+		if err := r.DB.ScanRows(rows, &movie); err != nil {
+			return err
+		}
+		batch = append(batch, movie)
+		if len(batch) == streamBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// Search matches movies against a to_tsquery expression and returns them
+// ordered by relevance, with highlighted fragments of the plot.
+func (r *MovieRepository) Search(tsQuery string, page models.PageQuery) ([]models.MovieSearchHit, int64, error) {
+	db := r.DB.Model(&models.Movie{}).
+		Joins("CROSS JOIN to_tsquery('english', ?) AS query", tsQuery).
+		Where("movies.search_vector @@ query").
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []models.MovieSearchHit
+	result := db.Select(`movies.id, movies.title, movies.director, movies.year, movies.plot, movies.genre,
+			movies.rating, movies.average_rating, movies.vote_count, movies.version,
+			movies.poster, movies.backdrop, movies.user_id, movies.created_at, movies.updated_at,
+			ts_rank(movies.search_vector, query) AS rank,
+			ts_headline('english', coalesce(movies.plot, ''), query,
+				'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2') AS snippet`).
+		Order("rank DESC, movies.id ASC").
+		Limit(page.Limit).
+		Offset((page.Page - 1) * page.Limit).
+		Scan(&hits)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	genres, err := r.genresByMovie(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range hits {
+		hits[i].Genres = genres[hits[i].ID]
+	}
+
+	return hits, total, nil
 }
 
 func (r *MovieRepository) GetByID(id uint) (models.Movie, error) {
 	var movie models.Movie
-	result := r.DB.First(&movie, id)
+	result := r.DB.Preload("Genres", orderGenres).First(&movie, id)
+	return movie, result.Error
+}
+
+// GetForUpdate loads a movie and locks its row until the transaction ends.
+func (r *MovieRepository) GetForUpdate(id uint) (models.Movie, error) {
+	var movie models.Movie
+	result := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).First(&movie, id)
 	return movie, result.Error
 }
 
@@ -32,11 +156,107 @@ func (r *MovieRepository) Create(movie *models.Movie) error {
 }
 
 func (r *MovieRepository) Update(movie *models.Movie) error {
-	result := r.DB.Save(movie)
+	// Review aggregates are maintained by ReviewRepository.RefreshMovieRating
+	// and images by ImageService.
+	result := r.DB.Omit("average_rating", "vote_count", "poster", "backdrop", clause.Associations).Save(movie)
+	if result.Error != nil {
+		return result.Error
+	}
 	if result.RowsAffected == 0 {
 		return errors.New("movie not found")
 	}
-	return result.Error
+	return r.ReplaceGenres(movie)
+}
+
+// UpdateColumns saves only the named columns of a movie.
+func (r *MovieRepository) UpdateColumns(movie *models.Movie, columns []string) error {
+	return r.DB.Model(movie).Select(columns).Updates(movie).Error
+}
+
+// ListDeleted returns a page of a user's soft-deleted movies, most recently
+// deleted first.
+func (r *MovieRepository) ListDeleted(userID uint, page models.PageQuery) ([]models.Movie, int64, error) {
+	db := r.DB.Unscoped().Model(&models.Movie{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var movies []models.Movie
+	result := db.Preload("Genres", orderGenres).
+		Order("deleted_at DESC, id DESC").
+		Limit(page.Limit).
+		Offset((page.Page - 1) * page.Limit).
+		Find(&movies)
+	return movies, total, result.Error
+}
+
+// GetDeletedForUpdate loads a soft-deleted movie and locks its row until
+// the transaction ends.
+func (r *MovieRepository) GetDeletedForUpdate(id uint) (models.Movie, error) {
+	var movie models.Movie
+	result := r.DB.Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Genres", orderGenres).
+		Where("deleted_at IS NOT NULL").
+		First(&movie, id)
+	return movie, result.Error
+}
+
+// GetAnyForUpdate loads a movie whether or not it is soft-deleted and locks
+// its row until the transaction ends.
+func (r *MovieRepository) GetAnyForUpdate(id uint) (models.Movie, error) {
+	var movie models.Movie
+	result := r.DB.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&movie, id)
+	return movie, result.Error
+}
+
+// Restore clears the deletion mark of a movie and saves who restored it.
+func (r *MovieRepository) Restore(movie *models.Movie) error {
+	movie.DeletedAt = gorm.DeletedAt{}
+	return r.DB.Unscoped().Model(movie).
+		Select("deleted_at", "modified_by_id", "modified_via", "version", "updated_at").
+		Updates(movie).Error
+}
+
+// HardDelete permanently removes a movie and every row that refers to it,
+// including its revisions.
+func (r *MovieRepository) HardDelete(id uint) error {
+	for _, table := range []string{
+		"movie_genres", "credits", "reviews", "watchlist_entries",
+		"diary_entries", "list_items", "movie_revisions",
+	} {
+		if err := r.DB.Exec("DELETE FROM "+table+" WHERE movie_id = ?", id).Error; err != nil {
+			return err
+		}
+	}
+	return r.DB.Unscoped().Delete(&models.Movie{}, id).Error
+}
+
+// DeletedBefore returns the IDs of up to limit movies soft-deleted before
+// cutoff, oldest first.
+func (r *MovieRepository) DeletedBefore(cutoff time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	result := r.DB.Unscoped().Model(&models.Movie{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Pluck("id", &ids)
+	return ids, result.Error
+}
+
+// BumpVersion marks a change to data shown with the movie, such as its
+// credits, so that cached copies are revalidated.
+func (r *MovieRepository) BumpVersion(id uint) error {
+	return r.DB.Model(&models.Movie{}).Where("id = ?", id).
+		UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
+
+func (r *MovieRepository) ReplaceGenres(movie *models.Movie) error {
+	return r.DB.Model(movie).Association("Genres").Replace(movie.Genres)
 }
 
 func (r *MovieRepository) Delete(id uint) error {
@@ -47,8 +267,96 @@ func (r *MovieRepository) Delete(id uint) error {
 	return result.Error
 }
 
-func (r *MovieRepository) FindByUserID(userID uint) ([]models.Movie, error) {
-	var movies []models.Movie
-	result := r.DB.Where("user_id = ?", userID).Find(&movies)
-	return movies, result.Error
+func (r *MovieRepository) GetUserStats(userID uint, topGenres int) (models.UserMovieStats, error) {
+	var totals struct {
+		MovieCount    int64
+		AverageRating float64
+	}
+	result := userTotals(r.DB, userID).Scan(&totals)
+	if result.Error != nil {
+		return models.UserMovieStats{}, result.Error
+	}
+
+	genres := []models.GenreCount{}
+	result = r.DB.Model(&models.Movie{}).
+		Select("genres.name AS genre, COUNT(*) AS count").
+		Joins("JOIN movie_genres ON movie_genres.movie_id = movies.id").
+		Joins("JOIN genres ON genres.id = movie_genres.genre_id").
+		Where("movies.user_id = ?", userID).
+		Group("genres.name").
+		Order("count DESC, genres.name ASC").
+		Limit(topGenres).
+		Scan(&genres)
+	if result.Error != nil {
+		return models.UserMovieStats{}, result.Error
+	}
+
+	return models.UserMovieStats{
+		MovieCount:    totals.MovieCount,
+		AverageRating: totals.AverageRating,
+		TopGenres:     genres,
+	}, nil
+}
+
+// userTotals counts a user's movies and averages their review ratings.
+// Movies without reviews are left out of the average rather than counted
+// as 0.
+func userTotals(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&models.Movie{}).
+		Select("COUNT(*) AS movie_count, COALESCE(AVG(average_rating) FILTER (WHERE vote_count > 0), 0) AS average_rating").
+		Where("user_id = ?", userID)
+}
+
+func applyMovieFilters(db *gorm.DB, query models.MovieListQuery) *gorm.DB {
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Genre != "" {
+		db = db.Where(`EXISTS (
+			SELECT 1 FROM movie_genres
+			JOIN genres ON genres.id = movie_genres.genre_id
+			WHERE movie_genres.movie_id = movies.id AND genres.slug = ?)`, query.Genre)
+	}
+	if query.Director != "" {
+		db = db.Where("director ILIKE ?", "%"+query.Director+"%")
+	}
+	if query.MinYear > 0 {
+		db = db.Where("year >= ?", query.MinYear)
+	}
+	if query.MaxYear > 0 {
+		db = db.Where("year <= ?", query.MaxYear)
+	}
+	if query.MinRating > 0 {
+		db = db.Where("average_rating >= ?", query.MinRating)
+	}
+	if query.MaxRating > 0 {
+		db = db.Where("average_rating <= ?", query.MaxRating)
+	}
+	return db
+}
+
+func (r *MovieRepository) genresByMovie(movieIDs []uint) (map[uint][]models.Genre, error) {
+	var rows []struct {
+		MovieID uint
+		models.Genre
+	}
+	result := r.DB.Table("movie_genres").
+		Select("movie_genres.movie_id, genres.id, genres.name, genres.slug, genres.created_at").
+		Joins("JOIN genres ON genres.id = movie_genres.genre_id").
+		Where("movie_genres.movie_id IN ?", movieIDs).
+		Order("genres.name ASC").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	genres := make(map[uint][]models.Genre)
+	for _, row := range rows {
+		genres[row.MovieID] = append(genres[row.MovieID], row.Genre)
+	}
+	return genres, nil
+}
+
+func orderGenres(db *gorm.DB) *gorm.DB {
+	return db.Order("genres.name ASC")
 }
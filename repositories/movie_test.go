@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns a PostgreSQL session that builds statements without
+// connecting, for tests that check the generated SQL.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestUserTotals(t *testing.T) {
+	db := dryRunDB(t)
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var totals struct {
+			MovieCount    int64
+			AverageRating float64
+		}
+		return userTotals(tx, 7).Scan(&totals)
+	})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"averages review ratings", "AVG(average_rating)"},
+		{"skips movies without reviews", "FILTER (WHERE vote_count > 0)"},
+		{"filters by owner", "user_id = 7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(sql, tt.want) {
+				t.Errorf("userTotals SQL = %q, want it to contain %q", sql, tt.want)
+			}
+		})
+	}
+	if strings.Contains(sql, "AVG(rating)") {
+		t.Errorf("userTotals SQL = %q, averages the legacy rating column", sql)
+	}
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository struct {
+	DB *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{DB: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *PasswordResetRepository) WithTx(tx *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{DB: tx}
+}
+
+func (r *PasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *PasswordResetRepository) FindByHash(hash string) (models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	result := r.DB.Where("token_hash = ?", hash).First(&token)
+	return token, result.Error
+}
+
+// MarkUsed consumes the token. It reports false when the token was already
+// used, so two concurrent resets cannot both succeed.
+func (r *PasswordResetRepository) MarkUsed(id uint) (bool, error) {
+	result := r.DB.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// InvalidateUser consumes every outstanding token of the user.
+func (r *PasswordResetRepository) InvalidateUser(userID uint) error {
+	return r.DB.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PersonRepository struct {
+	DB *gorm.DB
+}
+
+func NewPersonRepository(db *gorm.DB) *PersonRepository {
+	return &PersonRepository{DB: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *PersonRepository) WithTx(tx *gorm.DB) *PersonRepository {
+	return &PersonRepository{DB: tx}
+}
+
+func (r *PersonRepository) List(name string, page models.PageQuery) ([]models.Person, int64, error) {
+	db := r.DB.Model(&models.Person{})
+	if name != "" {
+		db = db.Where("name ILIKE ?", "%"+name+"%")
+	}
+	db = db.Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var people []models.Person
+	result := db.Order("name ASC, id ASC").
+		Limit(page.Limit).
+		Offset((page.Page - 1) * page.Limit).
+		Find(&people)
+	return people, total, result.Error
+}
+
+func (r *PersonRepository) GetByID(id uint) (models.Person, error) {
+	var person models.Person
+	result := r.DB.First(&person, id)
+	return person, result.Error
+}
+
+// FindOrCreateByName returns the person with the given name, ignoring case,
+// creating one when there is none. Names are unique ignoring case, so a
+// concurrent call creating the same person makes the insert a no-op and the
+// existing record is returned instead.
+func (r *PersonRepository) FindOrCreateByName(name string, createdByID uint) (models.Person, error) {
+	name = strings.TrimSpace(name)
+	person, err := r.findByName(name)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return person, err
+	}
+
+	person = models.Person{Name: name, CreatedByID: createdByID}
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&person)
+	if result.Error != nil || result.RowsAffected == 1 {
+		return person, result.Error
+	}
+	return r.findByName(name)
+}
+
+func (r *PersonRepository) findByName(name string) (models.Person, error) {
+	var person models.Person
+	result := r.DB.Where("LOWER(name) = LOWER(?)", name).First(&person)
+	return person, result.Error
+}
+
+// Create inserts a person, returning gorm.ErrDuplicatedKey when one with the
+// same name already exists.
+func (r *PersonRepository) Create(person *models.Person) error {
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(person)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrDuplicatedKey
+	}
+	return result.Error
+}
+
+// Update saves a person, returning gorm.ErrDuplicatedKey when another person
+// already has the same name.
+func (r *PersonRepository) Update(person *models.Person) error {
+	if err := r.DB.Save(person).Error; err != nil {
+		if isUniqueViolation(err) {
+			return gorm.ErrDuplicatedKey
+		}
+		return err
+	}
+	return r.bumpMovieVersions(person.ID)
+}
+
+// Delete removes a person together with their credits.
+func (r *PersonRepository) Delete(id uint) error {
+	if err := r.bumpMovieVersions(id); err != nil {
+		return err
+	}
+	if err := r.DB.Where("person_id = ?", id).Delete(&models.Credit{}).Error; err != nil {
+		return err
+	}
+	return r.DB.Delete(&models.Person{}, id).Error
+}
+
+// Filmography returns the credits of a person on movies that are not
+// deleted, newest first.
+func (r *PersonRepository) Filmography(personID uint) ([]models.Credit, error) {
+	var credits []models.Credit
+	result := r.DB.InnerJoins("Movie").
+		Where(`credits.person_id = ? AND "Movie".deleted_at IS NULL`, personID).
+		Order(`"Movie".year DESC, "Movie".id DESC, credits.position ASC`).
+		Find(&credits)
+	return credits, result.Error
+}
+
+func (r *PersonRepository) MovieCredits(movieID uint) ([]models.Credit, error) {
+	var credits []models.Credit
+	result := r.DB.InnerJoins("Person").
+		Where("credits.movie_id = ?", movieID).
+		Order("credits.position ASC, credits.id ASC").
+		Find(&credits)
+	return credits, result.Error
+}
+
+func (r *PersonRepository) GetCredit(movieID, id uint) (models.Credit, error) {
+	var credit models.Credit
+	result := r.DB.InnerJoins("Person").Where("credits.movie_id = ?", movieID).First(&credit, "credits.id = ?", id)
+	return credit, result.Error
+}
+
+func (r *PersonRepository) CreateCredit(credit *models.Credit) error {
+	return r.DB.Omit(clause.Associations).Create(credit).Error
+}
+
+func (r *PersonRepository) DeleteCredit(id uint) error {
+	return r.DB.Delete(&models.Credit{}, id).Error
+}
+
+// RemoveDirectors deletes the director credits of a movie for the people
+// with the given names, ignoring case.
+func (r *PersonRepository) RemoveDirectors(movieID uint, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+	people := r.DB.Model(&models.Person{}).Select("id").Where("LOWER(name) IN ?", lowered)
+	return r.DB.Where("movie_id = ? AND role = ? AND person_id IN (?)", movieID, models.CreditDirector, people).
+		Delete(&models.Credit{}).Error
+}
+
+// AddDirectors credits the given people as directors of a movie, positioned
+// in their order, skipping those already credited as director.
+func (r *PersonRepository) AddDirectors(movieID uint, people []models.Person) error {
+	for i, person := range people {
+		var credited int64
+		err := r.DB.Model(&models.Credit{}).
+			Where("movie_id = ? AND person_id = ? AND role = ?", movieID, person.ID, models.CreditDirector).
+			Count(&credited).Error
+		if err != nil {
+			return err
+		}
+		if credited > 0 {
+			continue
+		}
+
+		credit := models.Credit{
+			MovieID:  movieID,
+			PersonID: person.ID,
+			Role:     models.CreditDirector,
+			Position: i,
+		}
+		if err := r.CreateCredit(&credit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bumpMovieVersions bumps the version of every movie crediting the person,
+// since their credits are shown with the movie.
+func (r *PersonRepository) bumpMovieVersions(personID uint) error {
+	return r.DB.Exec(
+		"UPDATE movies SET version = version + 1 WHERE id IN (SELECT movie_id FROM credits WHERE person_id = ?)",
+		personID,
+	).Error
+}
+
+// isUniqueViolation reports whether err is PostgreSQL's unique_violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
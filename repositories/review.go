@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewRepository struct {
+	DB *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{DB: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *ReviewRepository) WithTx(tx *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{DB: tx}
+}
+
+func (r *ReviewRepository) ListByMovie(movieID uint, page models.PageQuery) ([]models.Review, int64, error) {
+	db := r.DB.Model(&models.Review{}).Where("movie_id = ?", movieID).Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []models.Review
+	result := db.Preload("User").
+		Order("created_at DESC, id DESC").
+		Limit(page.Limit).
+		Offset((page.Page - 1) * page.Limit).
+		Find(&reviews)
+	return reviews, total, result.Error
+}
+
+func (r *ReviewRepository) GetByID(movieID, id uint) (models.Review, error) {
+	var review models.Review
+	result := r.DB.Preload("User").Where("movie_id = ?", movieID).First(&review, id)
+	return review, result.Error
+}
+
+func (r *ReviewRepository) ExistsForUser(movieID, userID uint) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.Review{}).
+		Where("movie_id = ? AND user_id = ?", movieID, userID).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+func (r *ReviewRepository) Create(review *models.Review) error {
+	return r.DB.Omit(clause.Associations).Create(review).Error
+}
+
+func (r *ReviewRepository) Update(review *models.Review) error {
+	return r.DB.Model(review).Select("score", "text").Updates(review).Error
+}
+
+func (r *ReviewRepository) Delete(id uint) error {
+	return r.DB.Delete(&models.Review{}, id).Error
+}
+
+// RefreshMovieRating recomputes the cached average score and vote count of
+// a movie from its reviews. It leaves the movie's version alone, so that
+// reviews by other users don't invalidate an editor's If-Match tag.
+func (r *ReviewRepository) RefreshMovieRating(movieID uint) error {
+	return r.DB.Exec(`
+		UPDATE movies SET
+			average_rating = COALESCE((SELECT AVG(score) FROM reviews WHERE movie_id = @id), 0),
+			vote_count = (SELECT COUNT(*) FROM reviews WHERE movie_id = @id)
+		WHERE id = @id`,
+		map[string]interface{}{"id": movieID},
+	).Error
+}
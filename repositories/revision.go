@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevisionRepository stores movie revisions. Revisions are never updated;
+// the database rejects updates as well.
+type RevisionRepository struct {
+	DB *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) *RevisionRepository {
+	return &RevisionRepository{DB: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *RevisionRepository) WithTx(tx *gorm.DB) *RevisionRepository {
+	return &RevisionRepository{DB: tx}
+}
+
+// List returns a page of a movie's revisions, newest first.
+func (r *RevisionRepository) List(movieID uint, page models.PageQuery) ([]models.MovieRevision, int64, error) {
+	db := r.DB.Model(&models.MovieRevision{}).Where("movie_id = ?", movieID).Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var revisions []models.MovieRevision
+	result := db.Preload("Editor").
+		Order("revision DESC").
+		Limit(page.Limit).
+		Offset((page.Page - 1) * page.Limit).
+		Find(&revisions)
+	return revisions, total, result.Error
+}
+
+func (r *RevisionRepository) Get(movieID uint, revision int) (models.MovieRevision, error) {
+	var rev models.MovieRevision
+	result := r.DB.Preload("Editor").
+		Where("movie_id = ? AND revision = ?", movieID, revision).
+		First(&rev)
+	return rev, result.Error
+}
+
+// Append stores rev as the movie's next revision. Callers hold the movie's
+// row lock, which keeps revision numbers gapless.
+func (r *RevisionRepository) Append(rev *models.MovieRevision) error {
+	var last int
+	err := r.DB.Model(&models.MovieRevision{}).
+		Where("movie_id = ?", rev.MovieID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&last).Error
+	if err != nil {
+		return err
+	}
+
+	rev.Revision = last + 1
+	return r.DB.Omit(clause.Associations).Create(rev).Error
+}
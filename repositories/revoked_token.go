@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedTokenRepository is the Postgres-backed access token denylist.
+type RevokedTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{DB: db}
+}
+
+func (r *RevokedTokenRepository) Revoke(tokenID string, expiresAt time.Time) error {
+	err := r.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedToken{TokenID: tokenID, ExpiresAt: expiresAt}).Error
+	if err != nil {
+		return err
+	}
+
+	// Expired entries can never match a valid token, so drop them here.
+	return r.DB.Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error
+}
+
+func (r *RevokedTokenRepository) IsRevoked(tokenID string) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.RevokedToken{}).
+		Where("token_id = ? AND expires_at > ?", tokenID, time.Now()).
+		Count(&count)
+	return count > 0, result.Error
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{DB: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *RefreshTokenRepository) WithTx(tx *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{DB: tx}
+}
+
+func (r *RefreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *RefreshTokenRepository) FindByHash(hash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	result := r.DB.Where("token_hash = ?", hash).First(&token)
+	return token, result.Error
+}
+
+// Revoke marks a single token as used. It reports false when the token had
+// already been revoked, which lets callers detect concurrent reuse.
+func (r *RefreshTokenRepository) Revoke(id uint) (bool, error) {
+	result := r.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(familyID string) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *RefreshTokenRepository) RevokeUser(userID uint) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/dostonshernazarov/movies-app/models"
 	"gorm.io/gorm"
 )
@@ -13,12 +15,70 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{DB: db}
 }
 
+// WithTx returns a repository bound to the given transaction.
+func (r *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return &UserRepository{DB: tx}
+}
+
 func (r *UserRepository) FindByUsername(username string) (models.User, error) {
 	var user models.User
 	result := r.DB.Where("username = ?", username).First(&user)
 	return user, result.Error
 }
 
+func (r *UserRepository) FindByID(id uint) (models.User, error) {
+	var user models.User
+	result := r.DB.First(&user, id)
+	return user, result.Error
+}
+
+func (r *UserRepository) FindByEmail(email string) (models.User, error) {
+	var user models.User
+	result := r.DB.Where("LOWER(email) = LOWER(?)", email).First(&user)
+	return user, result.Error
+}
+
 func (r *UserRepository) Create(user *models.User) error {
 	return r.DB.Create(user).Error
 }
+
+func (r *UserRepository) UpdateRole(id uint, role string) error {
+	result := r.DB.Model(&models.User{}).Where("id = ?", id).Update("role", role)
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+func (r *UserRepository) InvalidateTokens(id uint, before time.Time) error {
+	result := r.DB.Model(&models.User{}).Where("id = ?", id).Update("tokens_invalid_before", before)
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+func (r *UserRepository) UpdatePassword(id uint, hashedPassword string) error {
+	result := r.DB.Model(&models.User{}).Where("id = ?", id).Update("password", hashedPassword)
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+func (r *UserRepository) MarkEmailVerified(id uint, at time.Time) error {
+	result := r.DB.Model(&models.User{}).Where("id = ?", id).Update("email_verified_at", at)
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// ClaimVerificationSend records that a verification email is being sent. It
+// reports false when one was already sent after notBefore.
+func (r *UserRepository) ClaimVerificationSend(id uint, notBefore time.Time) (bool, error) {
+	result := r.DB.Model(&models.User{}).
+		Where("id = ? AND (verification_sent_at IS NULL OR verification_sent_at <= ?)", id, notBefore).
+		Update("verification_sent_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WatchlistRepository struct {
+	DB *gorm.DB
+}
+
+func NewWatchlistRepository(db *gorm.DB) *WatchlistRepository {
+	return &WatchlistRepository{DB: db}
+}
+
+// WithTx returns a repository bound to the given transaction.
+func (r *WatchlistRepository) WithTx(tx *gorm.DB) *WatchlistRepository {
+	return &WatchlistRepository{DB: tx}
+}
+
+// List returns a page of the user's watchlist in order. Entries for deleted
+// movies are skipped.
+func (r *WatchlistRepository) List(userID uint, page models.PageQuery) ([]models.WatchlistEntry, int64, error) {
+	db := r.DB.Model(&models.WatchlistEntry{}).
+		Where("user_id = ? AND movie_id IN (SELECT id FROM movies WHERE deleted_at IS NULL)", userID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.WatchlistEntry
+	result := db.Preload("Movie").
+		Preload("Movie.Genres", orderGenres).
+		Order("position ASC, id ASC").
+		Limit(page.Limit).
+		Offset((page.Page - 1) * page.Limit).
+		Find(&entries)
+	return entries, total, result.Error
+}
+
+// LockAll loads the user's whole watchlist in order and locks the rows
+// until the transaction ends. Entries for movies in the trash are included
+// so that renumbering keeps their slots for a restore.
+func (r *WatchlistRepository) LockAll(userID uint) ([]models.WatchlistEntry, error) {
+	var entries []models.WatchlistEntry
+	result := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		Order("position ASC, id ASC").
+		Find(&entries)
+	return entries, result.Error
+}
+
+// Exists reports whether the movie is on the user's watchlist.
+func (r *WatchlistRepository) Exists(userID, movieID uint) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.WatchlistEntry{}).
+		Where("user_id = ? AND movie_id = ?", userID, movieID).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+func (r *WatchlistRepository) Create(entry *models.WatchlistEntry) error {
+	return r.DB.Omit(clause.Associations).Create(entry).Error
+}
+
+func (r *WatchlistRepository) Delete(id uint) error {
+	return r.DB.Delete(&models.WatchlistEntry{}, id).Error
+}
+
+func (r *WatchlistRepository) UpdatePosition(id uint, position int) error {
+	return r.DB.Model(&models.WatchlistEntry{}).Where("id = ?", id).Update("position", position).Error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLockAllIncludesTrashedMovies(t *testing.T) {
+	db := dryRunDB(t)
+	queries := captureQueries(t, db)
+
+	if _, err := NewWatchlistRepository(db).LockAll(3); err != nil {
+		t.Fatal(err)
+	}
+	if len(*queries) != 1 {
+		t.Fatalf("LockAll ran %d queries, want 1", len(*queries))
+	}
+	if sql := (*queries)[0]; strings.Contains(sql, "deleted_at") {
+		t.Errorf("LockAll SQL = %q, want entries of trashed movies included", sql)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dostonshernazarov/movies-app/config"
+	"github.com/dostonshernazarov/movies-app/core"
+	"github.com/dostonshernazarov/movies-app/services"
+)
+
+const seedUsage = `usage: movies-app seed -owner <username> [-dry-run] <file>
+
+Loads movies from a JSON file holding an array of movies, or from a JSON
+Lines file with one movie per line. Movies have the fields of the movie API
+and belong to the owner. Sample data is in seeds/movies.json.`
+
+func runSeed(cfg *config.Config, args []string) error {
+	flags := newFlagSet("seed", seedUsage)
+	owner := flags.String("owner", "", "username of the user who will own the movies")
+	dryRun := flags.Bool("dry-run", false, "report what would be loaded without saving anything")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *owner == "" {
+		flags.Usage()
+		return errors.New("a seed file and -owner are required")
+	}
+
+	records, err := readSeedFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return core.Run(cfg, func(users *services.UserService, movies *services.MovieService) error {
+		user, err := users.GetUserByUsername(*owner)
+		if err != nil {
+			return fmt.Errorf("owner %q: %w", *owner, err)
+		}
+
+		imported, failures, err := movies.ImportRequests(records, user.ID, *dryRun)
+		if err != nil {
+			return err
+		}
+		for _, failure := range failures {
+			fmt.Fprintf(os.Stderr, "movie %d: %s\n", failure.Line, failure.Error)
+		}
+
+		verb := "Loaded"
+		if *dryRun {
+			verb = "Would load"
+		}
+		fmt.Printf("%s %d of %d movies for %s\n", verb, imported, len(records), user.Username)
+		if len(failures) > 0 {
+			return fmt.Errorf("%d movie(s) could not be loaded", len(failures))
+		}
+		return nil
+	})
+}
+
+// readSeedFile decodes a JSON array of movies or, when the file does not
+// start with "[", one movie per line. Records are numbered by their position
+// in the array or their line. Movies that fail to decode are returned with
+// their error so that the rest can still be loaded.
+func readSeedFile(name string) ([]services.ImportRecord, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		records := make([]services.ImportRecord, len(items))
+		for i, item := range items {
+			records[i].Line = i + 1
+			records[i].Err = json.Unmarshal(item, &records[i].Request)
+		}
+		return records, nil
+	}
+
+	var records []services.ImportRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		record := services.ImportRecord{Line: line}
+		record.Err = json.Unmarshal(text, &record.Request)
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/dostonshernazarov/movies-app/config"
+	"github.com/dostonshernazarov/movies-app/core"
+)
+
+const serveUsage = `usage: movies-app serve
+
+Starts the HTTP server on server.port. It refuses to start while migrations are
+pending, and shuts down gracefully on SIGINT or SIGTERM.`
+
+func runServe(cfg *config.Config, args []string) error {
+	flags := newFlagSet("serve", serveUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return errors.New("serve takes no arguments")
+	}
+
+	app, err := core.InitializeApp(cfg)
+	if err != nil {
+		return err
+	}
+
+	return app.Run()
+}
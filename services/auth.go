@@ -1,22 +1,73 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/dostonshernazarov/movies-app/config"
 	"github.com/dostonshernazarov/movies-app/models"
 	"github.com/dostonshernazarov/movies-app/repositories"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken   = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused    = errors.New("refresh token reuse detected, session revoked")
+	ErrInvalidAccessToken    = errors.New("invalid or expired token")
+	ErrAccessTokenRevoked    = errors.New("token has been revoked")
+	ErrInvalidResetToken     = errors.New("invalid or expired password reset token")
+	ErrInvalidVerification   = errors.New("invalid or expired verification link")
+	ErrAlreadyVerified       = errors.New("email is already verified")
+	ErrVerificationThrottled = errors.New("a verification email was sent recently, try again later")
 )
 
 type AuthService struct {
-	UserRepo   *repositories.UserRepository
-	JWTService *JWTService
+	UserRepo          *repositories.UserRepository
+	RefreshTokenRepo  *repositories.RefreshTokenRepository
+	PasswordResetRepo *repositories.PasswordResetRepository
+	JWTService        *JWTService
+	Revocations       RevocationStore
+	Mailer            Mailer
+	DB                *gorm.DB
+	refreshLifespan   time.Duration
+	resetLifespan     time.Duration
+	verifyLifespan    time.Duration
+	resendInterval    time.Duration
+	baseURL           string
+	// emails tracks password reset emails still being sent.
+	emails sync.WaitGroup
 }
 
-func NewAuthService(userRepo *repositories.UserRepository, jwtService *JWTService) *AuthService {
+func NewAuthService(
+	userRepo *repositories.UserRepository,
+	refreshTokenRepo *repositories.RefreshTokenRepository,
+	passwordResetRepo *repositories.PasswordResetRepository,
+	jwtService *JWTService,
+	revocations RevocationStore,
+	mailer Mailer,
+	db *gorm.DB,
+	cfg *config.Config,
+) *AuthService {
 	return &AuthService{
-		UserRepo:   userRepo,
-		JWTService: jwtService,
+		UserRepo:          userRepo,
+		RefreshTokenRepo:  refreshTokenRepo,
+		PasswordResetRepo: passwordResetRepo,
+		JWTService:        jwtService,
+		Revocations:       revocations,
+		Mailer:            mailer,
+		DB:                db,
+		refreshLifespan:   cfg.Auth.RefreshTokenLifespan,
+		resetLifespan:     cfg.Auth.PasswordResetLifespan,
+		verifyLifespan:    cfg.Auth.EmailVerificationLifespan,
+		resendInterval:    cfg.Auth.VerificationResendInterval,
+		baseURL:           cfg.Server.BaseURL,
 	}
 }
 
@@ -26,20 +77,399 @@ func (s *AuthService) Register(user *models.User) error {
 		return err
 	}
 	user.Password = string(hashedPassword)
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = nil
+	user.VerificationSentAt = &now
+	if err := s.UserRepo.Create(user); err != nil {
+		return err
+	}
+
+	// The account exists even if the email fails; the user can ask for
+	// another link.
+	if err := s.sendVerification(*user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// CreateUser adds an account on behalf of an operator. Its email counts as
+// verified, so no verification email is sent.
+func (s *AuthService) CreateUser(user *models.User) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hashedPassword)
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
 	return s.UserRepo.Create(user)
 }
 
-func (s *AuthService) Login(username, password string) (string, models.User, error) {
+// VerifyEmail marks the address in a verification link as verified.
+func (s *AuthService) VerifyEmail(token string) (models.User, error) {
+	userID, email, err := s.JWTService.ValidateVerificationToken(token)
+	if err != nil {
+		return models.User{}, ErrInvalidVerification
+	}
+
+	user, err := s.UserRepo.FindByID(userID)
+	if err != nil || !strings.EqualFold(user.Email, email) {
+		return models.User{}, ErrInvalidVerification
+	}
+	if user.EmailVerifiedAt != nil {
+		return user, nil
+	}
+
+	now := time.Now()
+	if err := s.UserRepo.MarkEmailVerified(user.ID, now); err != nil {
+		return models.User{}, err
+	}
+	user.EmailVerifiedAt = &now
+	return user, nil
+}
+
+// ResendVerification sends a new verification link, at most once per
+// VERIFICATION_RESEND_MINUTE_INTERVAL.
+func (s *AuthService) ResendVerification(userID uint) error {
+	user, err := s.UserRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+
+	claimed, err := s.UserRepo.ClaimVerificationSend(user.ID, time.Now().Add(-s.resendInterval))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrVerificationThrottled
+	}
+
+	return s.sendVerification(user)
+}
+
+func (s *AuthService) sendVerification(user models.User) error {
+	token, err := s.JWTService.GenerateVerificationToken(user, s.verifyLifespan)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s/auth/verify?token=%s\n",
+		user.Username, s.verifyLifespan, s.baseURL, url.QueryEscape(token),
+	)
+	return s.Mailer.Send(user.Email, "Verify your email address", body)
+}
+
+func (s *AuthService) Login(username, password string) (models.TokenPair, models.User, error) {
 	user, err := s.UserRepo.FindByUsername(username)
 	if err != nil {
-		return "", models.User{}, errors.New("user not found")
+		return models.TokenPair{}, models.User{}, errors.New("user not found")
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", models.User{}, errors.New("invalid credentials")
+		return models.TokenPair{}, models.User{}, errors.New("invalid credentials")
+	}
+
+	familyID, err := newFamilyID()
+	if err != nil {
+		return models.TokenPair{}, models.User{}, err
+	}
+
+	tokens, err := s.issueTokens(s.RefreshTokenRepo, user, familyID)
+	if err != nil {
+		return models.TokenPair{}, models.User{}, err
+	}
+	return tokens, user, nil
+}
+
+// Refresh rotates a refresh token: the presented token is revoked and a new
+// pair is issued in the same family. Presenting a token that was already
+// rotated revokes the entire family.
+func (s *AuthService) Refresh(refreshToken string) (models.TokenPair, models.User, error) {
+	stored, err := s.RefreshTokenRepo.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		return models.TokenPair{}, models.User{}, ErrInvalidRefreshToken
+	}
+
+	if stored.RevokedAt != nil {
+		if err := s.RefreshTokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			return models.TokenPair{}, models.User{}, err
+		}
+		return models.TokenPair{}, models.User{}, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return models.TokenPair{}, models.User{}, ErrInvalidRefreshToken
+	}
+
+	user, err := s.UserRepo.FindByID(stored.UserID)
+	if err != nil {
+		return models.TokenPair{}, models.User{}, ErrInvalidRefreshToken
+	}
+
+	var tokens models.TokenPair
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		repo := s.RefreshTokenRepo.WithTx(tx)
+
+		revoked, err := repo.Revoke(stored.ID)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			// Another request rotated this token first.
+			return ErrRefreshTokenReused
+		}
+
+		tokens, err = s.issueTokens(repo, user, stored.FamilyID)
+		return err
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if revokeErr := s.RefreshTokenRepo.RevokeFamily(stored.FamilyID); revokeErr != nil {
+			return models.TokenPair{}, models.User{}, revokeErr
+		}
+	}
+	if err != nil {
+		return models.TokenPair{}, models.User{}, err
+	}
+
+	return tokens, user, nil
+}
+
+// Authenticate validates an access token and rejects it when its jti is on
+// the denylist or it was issued before the user's session cut-off. The role
+// is read from the database rather than the token, so role changes take
+// effect on the next request.
+func (s *AuthService) Authenticate(tokenString string) (models.AccessClaims, error) {
+	token, err := s.JWTService.ValidateToken(tokenString)
+	if err != nil || !token.Valid {
+		return models.AccessClaims{}, ErrInvalidAccessToken
+	}
+
+	claims := models.AccessClaims{
+		UserID:    s.JWTService.ExtractUserID(token),
+		SessionID: s.JWTService.ExtractSessionID(token),
+		TokenID:   s.JWTService.ExtractTokenID(token),
+		IssuedAt:  s.JWTService.ExtractIssuedAt(token),
+		ExpiresAt: s.JWTService.ExtractExpiresAt(token),
+	}
+
+	if claims.TokenID != "" {
+		revoked, err := s.Revocations.IsRevoked(claims.TokenID)
+		if err != nil {
+			return models.AccessClaims{}, err
+		}
+		if revoked {
+			return models.AccessClaims{}, ErrAccessTokenRevoked
+		}
+	}
+
+	user, err := s.UserRepo.FindByID(claims.UserID)
+	if err != nil {
+		return models.AccessClaims{}, ErrInvalidAccessToken
+	}
+	if user.TokensInvalidBefore != nil && claims.IssuedAt.Before(*user.TokensInvalidBefore) {
+		return models.AccessClaims{}, ErrAccessTokenRevoked
+	}
+	claims.Role = user.Role
+	claims.EmailVerified = user.EmailVerifiedAt != nil
+
+	return claims, nil
+}
+
+// Logout revokes the presented access token and every refresh token issued
+// for its session.
+func (s *AuthService) Logout(claims models.AccessClaims) error {
+	if claims.TokenID != "" {
+		if err := s.Revocations.Revoke(claims.TokenID, claims.ExpiresAt); err != nil {
+			return err
+		}
+	}
+	if claims.SessionID == "" {
+		return nil
+	}
+	return s.RefreshTokenRepo.RevokeFamily(claims.SessionID)
+}
+
+// RevokeAllSessions invalidates every access and refresh token the user
+// currently holds.
+func (s *AuthService) RevokeAllSessions(userID uint) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		return s.revokeSessions(tx, userID)
+	})
+}
+
+// ForgotPassword emails a single-use reset link. Unknown addresses are
+// silently ignored so the endpoint cannot be used to discover accounts. For
+// the same reason the link is issued and sent in the background, so that
+// the response takes as long for every address, and failures are only
+// logged.
+func (s *AuthService) ForgotPassword(email string) error {
+	user, err := s.UserRepo.FindByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.emails.Add(1)
+	go func() {
+		defer s.emails.Done()
+		if err := s.sendPasswordReset(user); err != nil {
+			log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		}
+	}()
+	return nil
+}
+
+// WaitForEmails blocks until the password reset emails being sent in the
+// background are done, or ctx ends.
+func (s *AuthService) WaitForEmails(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.emails.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *AuthService) sendPasswordReset(user models.User) error {
+	token, hash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.PasswordResetRepo.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.resetLifespan),
+	})
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can only be used once.\n\n%s/reset-password?token=%s\n\nIf you did not ask for a reset, you can ignore this email.\n",
+		user.Username, s.resetLifespan, s.baseURL, url.QueryEscape(token),
+	)
+	return s.Mailer.Send(user.Email, "Reset your password", body)
+}
+
+// ResetPassword consumes a reset token, sets the new password and revokes
+// every session the user had.
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	stored, err := s.PasswordResetRepo.FindByHash(hashToken(token))
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		resets := s.PasswordResetRepo.WithTx(tx)
+
+		used, err := resets.MarkUsed(stored.ID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidResetToken
+		}
+		return s.setPassword(tx, stored.UserID, string(hashedPassword))
+	})
+}
+
+// SetPassword replaces a user's password without a reset token and revokes
+// every session the user had.
+func (s *AuthService) SetPassword(userID uint, newPassword string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		return s.setPassword(tx, userID, string(hashedPassword))
+	})
+}
+
+// IssueServiceToken returns an access token for a service account that
+// stays valid for lifespan and its expiry. It belongs to no session, so
+// there is no refresh token; revoking the user's sessions voids it.
+func (s *AuthService) IssueServiceToken(user models.User, lifespan time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(lifespan)
+	token := s.JWTService.GenerateTokenWithLifespan(user, "", lifespan)
+	if token == "" {
+		return "", time.Time{}, errors.New("failed to sign token")
+	}
+	return token, expiresAt, nil
+}
+
+// setPassword stores a hashed password, voids outstanding reset tokens and
+// revokes the user's sessions.
+func (s *AuthService) setPassword(tx *gorm.DB, userID uint, hashedPassword string) error {
+	if err := s.PasswordResetRepo.WithTx(tx).InvalidateUser(userID); err != nil {
+		return err
+	}
+	if err := s.UserRepo.WithTx(tx).UpdatePassword(userID, hashedPassword); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return s.revokeSessions(tx, userID)
+}
+
+func (s *AuthService) revokeSessions(tx *gorm.DB, userID uint) error {
+	if err := s.UserRepo.WithTx(tx).InvalidateTokens(userID, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return s.RefreshTokenRepo.WithTx(tx).RevokeUser(userID)
+}
+
+func (s *AuthService) issueTokens(repo *repositories.RefreshTokenRepository, user models.User, familyID string) (models.TokenPair, error) {
+	refreshToken, hash, err := newOpaqueToken()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	err = repo.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.refreshLifespan),
+	})
+	if err != nil {
+		return models.TokenPair{}, err
 	}
 
-	token := s.JWTService.GenerateToken(user)
-	return token, user, nil
+	return models.TokenPair{
+		AccessToken:  s.JWTService.GenerateToken(user, familyID),
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.JWTService.Lifespan().Seconds()),
+	}, nil
 }
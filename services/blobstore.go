@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dostonshernazarov/movies-app/config"
+)
+
+// LocalMediaRoute is where the HTTP server serves files of a LocalBlobStore.
+const LocalMediaRoute = "/media"
+
+// BlobStore keeps uploaded files, such as movie images, under slash-separated
+// keys and tells clients where to fetch them.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// NewBlobStore picks the implementation named by storage.driver: "s3" for an
+// S3-compatible object store, "local" keeps files in storage.media_dir.
+func NewBlobStore(cfg *config.Config) (BlobStore, error) {
+	if cfg.Storage.Driver == "s3" {
+		return NewS3BlobStore(cfg.Storage.S3)
+	}
+
+	return &LocalBlobStore{
+		Dir:     cfg.Storage.MediaDir,
+		BaseURL: cfg.Storage.MediaBaseURL,
+	}, nil
+}
+
+// LocalBlobStore keeps files in a directory that the server exposes at
+// LocalMediaRoute. It suits development and single-instance deployments.
+type LocalBlobStore struct {
+	Dir     string
+	BaseURL string
+}
+
+func (s *LocalBlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial file.
+	f, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalBlobStore) URL(key string) string {
+	return s.BaseURL + "/" + key
+}
+
+// path maps a key to a file in Dir, rejecting keys that would escape it.
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
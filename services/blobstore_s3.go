@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/dostonshernazarov/movies-app/config"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3BlobStore keeps files in a bucket of an S3-compatible object store, such
+// as AWS S3 or a local MinIO server. Objects are expected to be publicly
+// readable at PublicURL.
+type S3BlobStore struct {
+	Client    *minio.Client
+	Bucket    string
+	PublicURL string
+}
+
+func NewS3BlobStore(cfg config.S3Config) (*S3BlobStore, error) {
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	return &S3BlobStore{
+		Client:    client,
+		Bucket:    cfg.Bucket,
+		PublicURL: cfg.PublicURL,
+	}, nil
+}
+
+func (s *S3BlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	_, err := s.Client.PutObject(ctx, s.Bucket, key, body, size, minio.PutObjectOptions{
+		ContentType: contentType,
+		// Keys include a hash of the content, so objects never change.
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	return err
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	return s.Client.RemoveObject(ctx, s.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3BlobStore) URL(key string) string {
+	return s.PublicURL + "/" + key
+}
+
+func (s *S3BlobStore) Name() string {
+	return "storage"
+}
+
+// Check makes the store a HealthChecker; the bucket must exist.
+func (s *S3BlobStore) Check(ctx context.Context) error {
+	exists, err := s.Client.BucketExists(ctx, s.Bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("bucket %q does not exist", s.Bucket)
+	}
+	return nil
+}
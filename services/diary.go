@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDiaryEntryNotFound = errors.New("diary entry not found")
+	ErrFutureWatchDate    = errors.New("watched_on cannot be in the future")
+)
+
+type DiaryService struct {
+	DiaryRepo *repositories.DiaryRepository
+	MovieRepo *repositories.MovieRepository
+}
+
+func NewDiaryService(diaryRepo *repositories.DiaryRepository, movieRepo *repositories.MovieRepository) *DiaryService {
+	return &DiaryService{
+		DiaryRepo: diaryRepo,
+		MovieRepo: movieRepo,
+	}
+}
+
+func (s *DiaryService) ListDiary(userID uint, page *models.PageQuery) ([]models.DiaryEntry, int64, error) {
+	normalizePage(page)
+	return s.DiaryRepo.List(userID, *page)
+}
+
+// GetDiaryEntry returns one of the user's diary entries. Entries of other
+// users are reported as not found.
+func (s *DiaryService) GetDiaryEntry(userID, id uint) (models.DiaryEntry, error) {
+	entry, err := s.DiaryRepo.GetByID(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DiaryEntry{}, ErrDiaryEntryNotFound
+	}
+	return entry, err
+}
+
+// LogViewing records that the user watched a movie. A zero WatchedOn means
+// today.
+func (s *DiaryService) LogViewing(entry *models.DiaryEntry) error {
+	movie, err := s.MovieRepo.GetByID(entry.MovieID)
+	if err != nil {
+		return movieLookupError(err)
+	}
+
+	if entry.WatchedOn.IsZero() {
+		entry.WatchedOn = today()
+	}
+	if err := checkWatchDate(entry.WatchedOn); err != nil {
+		return err
+	}
+
+	if err := s.DiaryRepo.Create(entry); err != nil {
+		return err
+	}
+	entry.Movie = movie
+	return nil
+}
+
+func (s *DiaryService) UpdateDiaryEntry(entry *models.DiaryEntry) error {
+	if err := checkWatchDate(entry.WatchedOn); err != nil {
+		return err
+	}
+	return s.DiaryRepo.Update(entry)
+}
+
+func (s *DiaryService) DeleteDiaryEntry(userID, id uint) error {
+	entry, err := s.GetDiaryEntry(userID, id)
+	if err != nil {
+		return err
+	}
+	return s.DiaryRepo.Delete(entry.ID)
+}
+
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// checkWatchDate rejects dates after today. One day of slack covers clients
+// in time zones ahead of UTC.
+func checkWatchDate(watchedOn time.Time) error {
+	if watchedOn.After(today().AddDate(0, 0, 1)) {
+		return ErrFutureWatchDate
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/repositories"
+	"golang.org/x/text/unicode/norm"
+)
+
+var ErrInvalidGenre = errors.New("genre must contain a letter or digit")
+
+// genreAliases maps the slugs of common spellings to a canonical genre.
+// Keep in sync with the aliases in migrations/0002_backfill_legacy_data.up.sql,
+// which backfills genres written before they were normalised.
+var genreAliases = map[string]models.Genre{
+	"sci-fi":          {Slug: "science-fiction", Name: "Science Fiction"},
+	"scifi":           {Slug: "science-fiction", Name: "Science Fiction"},
+	"sf":              {Slug: "science-fiction", Name: "Science Fiction"},
+	"science-fiction": {Slug: "science-fiction", Name: "Science Fiction"},
+	"rom-com":         {Slug: "romantic-comedy", Name: "Romantic Comedy"},
+	"romcom":          {Slug: "romantic-comedy", Name: "Romantic Comedy"},
+	"romantic-comedy": {Slug: "romantic-comedy", Name: "Romantic Comedy"},
+	"doc":             {Slug: "documentary", Name: "Documentary"},
+	"docu":            {Slug: "documentary", Name: "Documentary"},
+	"documentary":     {Slug: "documentary", Name: "Documentary"},
+	"animated":        {Slug: "animation", Name: "Animation"},
+	"animation":       {Slug: "animation", Name: "Animation"},
+	"noir":            {Slug: "film-noir", Name: "Film Noir"},
+	"film-noir":       {Slug: "film-noir", Name: "Film Noir"},
+	"bio":             {Slug: "biography", Name: "Biography"},
+	"biopic":          {Slug: "biography", Name: "Biography"},
+	"biography":       {Slug: "biography", Name: "Biography"},
+	"musical":         {Slug: "musical", Name: "Musical"},
+	"music":           {Slug: "music", Name: "Music"},
+	"suspense":        {Slug: "thriller", Name: "Thriller"},
+	"thriller":        {Slug: "thriller", Name: "Thriller"},
+}
+
+type GenreService struct {
+	GenreRepo *repositories.GenreRepository
+}
+
+func NewGenreService(genreRepo *repositories.GenreRepository) *GenreService {
+	return &GenreService{
+		GenreRepo: genreRepo,
+	}
+}
+
+func (s *GenreService) ListGenres() ([]models.GenreWithCount, error) {
+	return s.GenreRepo.ListWithCounts()
+}
+
+// CanonicalGenre normalises a free-text genre name into its canonical slug
+// and display name. It returns an empty slug for names without letters or
+// digits.
+func CanonicalGenre(name string) models.Genre {
+	name = norm.NFC.String(strings.Join(strings.Fields(name), " "))
+	slug := genreSlug(name)
+	if alias, ok := genreAliases[slug]; ok {
+		return alias
+	}
+	return models.Genre{Slug: slug, Name: titleCase(name)}
+}
+
+// canonicalGenres canonicalises and de-duplicates genre names, keeping the
+// first spelling of each.
+func canonicalGenres(genres []models.Genre) []models.Genre {
+	seen := make(map[string]bool)
+	var result []models.Genre
+	for _, genre := range genres {
+		canonical := CanonicalGenre(genre.Name)
+		if canonical.Slug == "" || seen[canonical.Slug] {
+			continue
+		}
+		seen[canonical.Slug] = true
+		result = append(result, canonical)
+	}
+	return result
+}
+
+// genreFilter returns the slug to filter movie listings by. A genre without
+// letters or digits is rejected rather than ignored, so that it does not
+// match every movie.
+func genreFilter(genre string) (string, error) {
+	if genre == "" {
+		return "", nil
+	}
+	slug := CanonicalGenre(genre).Slug
+	if slug == "" {
+		return "", ErrInvalidGenre
+	}
+	return slug, nil
+}
+
+// genreSlug lowercases the name and collapses every run of characters other
+// than letters and digits, in any script, into a single dash, matching the
+// SQL used by the legacy genre migration. The name must be in NFC form.
+func genreSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+func titleCase(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// genreNames joins genre names for the denormalised movies.genre column,
+// stopping before the column limit.
+func genreNames(genres []models.Genre) string {
+	var names []string
+	length := 0
+	for _, genre := range genres {
+		if length+len(genre.Name)+2 > 100 {
+			break
+		}
+		names = append(names, genre.Name)
+		length += len(genre.Name) + 2
+	}
+	return strings.Join(names, ", ")
+}
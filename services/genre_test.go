@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dostonshernazarov/movies-app/models"
+)
+
+func TestCanonicalGenre(t *testing.T) {
+	tests := []struct {
+		name string
+		want models.Genre
+	}{
+		{"Drama", models.Genre{Slug: "drama", Name: "Drama"}},
+		{"  drama  ", models.Genre{Slug: "drama", Name: "Drama"}},
+		{"sci-fi", models.Genre{Slug: "science-fiction", Name: "Science Fiction"}},
+		{"Sci Fi", models.Genre{Slug: "science-fiction", Name: "Science Fiction"}},
+		{"SCIFI", models.Genre{Slug: "science-fiction", Name: "Science Fiction"}},
+		{"rom com", models.Genre{Slug: "romantic-comedy", Name: "Romantic Comedy"}},
+		{"dark   comedy", models.Genre{Slug: "dark-comedy", Name: "Dark Comedy"}},
+		{"film--noir", models.Genre{Slug: "film-noir", Name: "Film Noir"}},
+		{"Action & Adventure", models.Genre{Slug: "action-adventure", Name: "Action & Adventure"}},
+		{"Драма", models.Genre{Slug: "драма", Name: "Драма"}},
+		{"научная фантастика", models.Genre{Slug: "научная-фантастика", Name: "Научная Фантастика"}},
+		{"Comédie", models.Genre{Slug: "comédie", Name: "Comédie"}},
+		{"Come\u0301die", models.Genre{Slug: "comédie", Name: "Comédie"}},
+		{"Comédie-Dramatique", models.Genre{Slug: "comédie-dramatique", Name: "Comédie-dramatique"}},
+		{"時代劇", models.Genre{Slug: "時代劇", Name: "時代劇"}},
+		{"!!!", models.Genre{Slug: "", Name: "!!!"}},
+		{"", models.Genre{Slug: "", Name: ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanonicalGenre(tt.name); got != tt.want {
+				t.Errorf("CanonicalGenre(%q) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalGenres(t *testing.T) {
+	tests := []struct {
+		name   string
+		genres []string
+		want   []string
+	}{
+		{"empty", nil, nil},
+		{"keeps order", []string{"drama", "Crime"}, []string{"drama", "crime"}},
+		{"drops duplicates", []string{"Drama", "drama", "DRAMA"}, []string{"drama"}},
+		{"drops aliases of a kept genre", []string{"Science Fiction", "sci-fi", "sf"}, []string{"science-fiction"}},
+		{"drops names without a slug", []string{"--", "Drama"}, []string{"drama"}},
+		{"keeps non-Latin names", []string{"Драма", "драма", "Comédie"}, []string{"драма", "comédie"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genres := make([]models.Genre, len(tt.genres))
+			for i, name := range tt.genres {
+				genres[i] = models.Genre{Name: name}
+			}
+
+			var got []string
+			for _, genre := range canonicalGenres(genres) {
+				got = append(got, genre.Slug)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("canonicalGenres(%q) slugs = %q, want %q", tt.genres, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenreFilter(t *testing.T) {
+	tests := []struct {
+		genre   string
+		want    string
+		wantErr bool
+	}{
+		{genre: "", want: ""},
+		{genre: "Sci-Fi", want: "science-fiction"},
+		{genre: "Драма", want: "драма"},
+		{genre: "comédie", want: "comédie"},
+		{genre: "--", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.genre, func(t *testing.T) {
+			got, err := genreFilter(tt.genre)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidGenre) {
+					t.Fatalf("genreFilter(%q) error = %v, want ErrInvalidGenre", tt.genre, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("genreFilter(%q) error = %v", tt.genre, err)
+			}
+			if got != tt.want {
+				t.Errorf("genreFilter(%q) = %q, want %q", tt.genre, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenreNames(t *testing.T) {
+	long := strings.Repeat("a", 50)
+
+	tests := []struct {
+		name   string
+		genres []string
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"joins names", []string{"Crime", "Drama"}, "Crime, Drama"},
+		{"stops at the column limit", []string{long, long}, long},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genres := make([]models.Genre, len(tt.genres))
+			for i, name := range tt.genres {
+				genres[i] = models.Genre{Name: name}
+			}
+			if got := genreNames(genres); got != tt.want {
+				t.Errorf("genreNames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGenreAliasesMatchMigration checks that the legacy genre backfill
+// canonicalises genres the same way as the service.
+func TestGenreAliasesMatchMigration(t *testing.T) {
+	sql, err := os.ReadFile("../migrations/0002_backfill_legacy_data.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := regexp.MustCompile(`\('([^']+)', '([^']+)', '([^']+)'\)`)
+	migration := map[string]models.Genre{}
+	for _, match := range row.FindAllStringSubmatch(string(sql), -1) {
+		migration[match[1]] = models.Genre{Slug: match[2], Name: match[3]}
+	}
+
+	if !reflect.DeepEqual(migration, genreAliases) {
+		t.Errorf("aliases in the migration = %v, want %v", migration, genreAliases)
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"gorm.io/gorm"
+)
+
+// healthCheckTimeout bounds each check, so one hanging dependency cannot
+// outlast the probe's own timeout.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthChecker reports whether a dependency the app needs is usable.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// NewHealthCheck turns a function into a HealthChecker.
+func NewHealthCheck(name string, check func(ctx context.Context) error) HealthChecker {
+	return healthCheck{name: name, check: check}
+}
+
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c healthCheck) Name() string {
+	return c.name
+}
+
+func (c healthCheck) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// HealthRegistry holds the checks behind the readiness probe. Subsystems
+// with a dependency of their own, such as a cache, register a check for it.
+type HealthRegistry struct {
+	mu       sync.RWMutex
+	checkers []HealthChecker
+}
+
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{}
+}
+
+// Register adds a check. Names should be unique, as they key the report.
+func (r *HealthRegistry) Register(checker HealthChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checker)
+}
+
+// Check runs every registered check concurrently and reports each one's
+// outcome and latency. The report is failing if any check failed; the errors
+// are logged rather than reported.
+func (r *HealthRegistry) Check(ctx context.Context) models.HealthReport {
+	r.mu.RLock()
+	checkers := append([]HealthChecker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	report := models.HealthReport{
+		Status: models.HealthStatusOK,
+		Checks: make(map[string]models.HealthCheckResult, len(checkers)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range checkers {
+		wg.Add(1)
+		go func(checker HealthChecker) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := checker.Check(checkCtx)
+			result := models.HealthCheckResult{
+				Status:    models.HealthStatusOK,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = models.HealthStatusFailing
+				log.Printf("Health check %s failed: %v", checker.Name(), err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[checker.Name()] = result
+			if err != nil {
+				report.Status = models.HealthStatusFailing
+			}
+		}(checker)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// NewDatabaseCheck pings the connection pool.
+func NewDatabaseCheck(db *gorm.DB) HealthChecker {
+	return NewHealthCheck("database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+}
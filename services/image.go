@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/config"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/repositories"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("image must be a JPEG, PNG or WebP file")
+	ErrImageTooLarge    = errors.New("image exceeds the upload size or 50 megapixels")
+	ErrNoImage          = errors.New("movie has no such image")
+)
+
+const (
+	// maxImagePixels guards against images that are small on disk but
+	// enormous once decoded.
+	maxImagePixels = 50_000_000
+	thumbnailJPEG  = 85
+)
+
+// imageExtensions are the accepted upload types, detected from the content
+// rather than the client's Content-Type header.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// thumbnailWidths are the widths generated for each kind of image.
+var thumbnailWidths = map[string][]int{
+	models.ImagePoster:   {185, 342, 780},
+	models.ImageBackdrop: {300, 780, 1280},
+}
+
+type ImageService struct {
+	MovieRepo *repositories.MovieRepository
+	Store     BlobStore
+	DB        *gorm.DB
+	MaxBytes  int64
+}
+
+func NewImageService(movieRepo *repositories.MovieRepository, store BlobStore, db *gorm.DB, cfg *config.Config) *ImageService {
+	return &ImageService{
+		MovieRepo: movieRepo,
+		Store:     store,
+		DB:        db,
+		MaxBytes:  int64(cfg.Storage.MaxUploadMB) << 20,
+	}
+}
+
+// SaveImage stores data as the movie's poster or backdrop, together with its
+// thumbnails, and replaces any previous image. movie.Version must match the
+// stored version.
+func (s *ImageService) SaveImage(ctx context.Context, movie *models.Movie, kind string, data []byte, actor models.Actor) error {
+	grant, err := authorizeChange(movie.UserID, actor)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > s.MaxBytes {
+		return ErrImageTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return ErrUnsupportedImage
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width == 0 || config.Height == 0 {
+		return ErrUnsupportedImage
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return ErrImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ErrUnsupportedImage
+	}
+
+	// Uploading the image a movie already has produces the same keys, so
+	// cleanup after a failure must spare the files it shares with it.
+	before := *imageOf(movie, kind)
+
+	sum := sha256.Sum256(data)
+	prefix := fmt.Sprintf("movies/%d/%s/%s", movie.ID, kind, hex.EncodeToString(sum[:8]))
+	set := models.ImageSet{
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+		Keys:        map[string]string{},
+		URLs:        map[string]string{},
+	}
+
+	if err := s.put(ctx, &set, models.ImageOriginal, prefix+"/original"+ext, data, contentType); err != nil {
+		deleteImageObjects(s.Store, withoutKeys(set, before))
+		return err
+	}
+	for _, width := range thumbnailWidths[kind] {
+		thumbnail, err := encodeThumbnail(img, width)
+		if err == nil {
+			size := "w" + strconv.Itoa(width)
+			err = s.put(ctx, &set, size, prefix+"/"+size+".jpg", thumbnail, "image/jpeg")
+		}
+		if err != nil {
+			deleteImageObjects(s.Store, withoutKeys(set, before))
+			return err
+		}
+	}
+
+	previous, err := s.setImage(movie, kind, set, actor.UserID, grant)
+	if err != nil {
+		deleteImageObjects(s.Store, withoutKeys(set, before))
+		return err
+	}
+	deleteImageObjects(s.Store, withoutKeys(previous, set))
+	return nil
+}
+
+// DeleteImage removes the movie's poster or backdrop and its thumbnails.
+func (s *ImageService) DeleteImage(movie *models.Movie, kind string, actor models.Actor) error {
+	grant, err := authorizeChange(movie.UserID, actor)
+	if err != nil {
+		return err
+	}
+	if imageOf(movie, kind).Empty() {
+		return ErrNoImage
+	}
+
+	previous, err := s.setImage(movie, kind, models.ImageSet{}, actor.UserID, grant)
+	if err != nil {
+		return err
+	}
+	deleteImageObjects(s.Store, previous)
+	return nil
+}
+
+// setImage saves set as the movie's image of the given kind and returns the
+// image it replaced, whose files the caller should delete once the change
+// is committed.
+func (s *ImageService) setImage(movie *models.Movie, kind string, set models.ImageSet, actorID uint, grant string) (models.ImageSet, error) {
+	var previous models.ImageSet
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		repo := s.MovieRepo.WithTx(tx)
+		current, err := repo.GetForUpdate(movie.ID)
+		if err != nil {
+			return movieLookupError(err)
+		}
+		if current.Version != movie.Version {
+			return ErrVersionConflict
+		}
+
+		previous = *imageOf(&current, kind)
+		*imageOf(movie, kind) = set
+		movie.ModifiedByID = actorID
+		movie.ModifiedVia = grant
+		movie.Version++
+		movie.UpdatedAt = time.Now()
+		return repo.UpdateColumns(movie, []string{kind, "modified_by_id", "modified_via", "version", "updated_at"})
+	})
+	return previous, err
+}
+
+func (s *ImageService) put(ctx context.Context, set *models.ImageSet, size, key string, data []byte, contentType string) error {
+	if err := s.Store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return fmt.Errorf("failed to store image: %w", err)
+	}
+	set.Keys[size] = key
+	set.URLs[size] = s.Store.URL(key)
+	return nil
+}
+
+// deleteImageObjects removes the files of images. Failures only leave
+// orphaned files behind, so they are logged rather than returned.
+func deleteImageObjects(store BlobStore, sets ...models.ImageSet) {
+	for _, set := range sets {
+		for _, key := range set.Keys {
+			if err := store.Delete(context.Background(), key); err != nil {
+				log.Printf("Failed to delete image %s: %v", key, err)
+			}
+		}
+	}
+}
+
+// withoutKeys returns set without the files it shares with other.
+func withoutKeys(set, other models.ImageSet) models.ImageSet {
+	keys := map[string]string{}
+	for size, key := range set.Keys {
+		if other.Keys[size] != key {
+			keys[size] = key
+		}
+	}
+	set.Keys = keys
+	return set
+}
+
+func imageOf(movie *models.Movie, kind string) *models.ImageSet {
+	if kind == models.ImageBackdrop {
+		return &movie.Backdrop
+	}
+	return &movie.Poster
+}
+
+// encodeThumbnail scales img to the given width, never enlarging it, and
+// encodes it as JPEG on a white background.
+func encodeThumbnail(img image.Image, width int) ([]byte, error) {
+	bounds := img.Bounds()
+	if width > bounds.Dx() {
+		width = bounds.Dx()
+	}
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailJPEG}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
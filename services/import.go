@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+const importBatchSize = 100
+
+// errDryRun rolls back a batch of a dry-run import.
+var errDryRun = errors.New("dry run")
+
+// ImportRecord is a movie request decoded from an import file, with the
+// line it starts on. Err is set when the record could not be decoded.
+type ImportRecord struct {
+	Line    int
+	Request models.MovieRequest
+	Err     error
+}
+
+// ImportRow is a movie read from an import file with the line it starts on.
+type ImportRow struct {
+	Line  int
+	Movie models.Movie
+}
+
+// ImportMovies creates the movies in rows for userID, one transaction per
+// batch. A row that fails is rolled back on its own and reported by line
+// while the rest of its batch is kept. A dry run rolls back every batch, so
+// its report matches what a real import would do.
+func (s *MovieService) ImportMovies(rows []ImportRow, userID uint, dryRun bool) (int, []models.ImportRowError, error) {
+	imported := 0
+	var failures []models.ImportRowError
+
+	for start := 0; start < len(rows); start += importBatchSize {
+		batch := rows[start:min(start+importBatchSize, len(rows))]
+
+		var batchImported int
+		var batchFailures []models.ImportRowError
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			for i := range batch {
+				row := &batch[i]
+				row.Movie.UserID = userID
+
+				// A nested transaction is a savepoint, so a failed row does
+				// not abort the batch.
+				err := tx.Transaction(func(tx *gorm.DB) error {
+					return s.createMovie(tx, &row.Movie)
+				})
+				if err != nil {
+					batchFailures = append(batchFailures, models.ImportRowError{Line: row.Line, Error: err.Error()})
+					continue
+				}
+				batchImported++
+			}
+
+			if dryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			return imported, failures, err
+		}
+
+		imported += batchImported
+		failures = append(failures, batchFailures...)
+	}
+
+	return imported, failures, nil
+}
+
+// ImportRequests validates decoded records like movie API requests and
+// imports the valid ones with ImportMovies. Records that fail to decode,
+// validate or save are reported together, ordered by line.
+func (s *MovieService) ImportRequests(records []ImportRecord, userID uint, dryRun bool) (int, []models.ImportRowError, error) {
+	var rows []ImportRow
+	failures := []models.ImportRowError{}
+	for _, record := range records {
+		if err := validateImportRecord(record); err != nil {
+			failures = append(failures, models.ImportRowError{Line: record.Line, Error: err.Error()})
+			continue
+		}
+
+		genres := make([]models.Genre, len(record.Request.Genres))
+		for i, name := range record.Request.Genres {
+			genres[i] = models.Genre{Name: name}
+		}
+		rows = append(rows, ImportRow{
+			Line: record.Line,
+			Movie: models.Movie{
+				Title:    record.Request.Title,
+				Director: record.Request.Director,
+				Year:     record.Request.Year,
+				Plot:     record.Request.Plot,
+				Genres:   genres,
+			},
+		})
+	}
+
+	imported, rowFailures, err := s.ImportMovies(rows, userID, dryRun)
+	if err != nil {
+		return imported, nil, err
+	}
+
+	failures = append(failures, rowFailures...)
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].Line < failures[j].Line
+	})
+	return imported, failures, nil
+}
+
+// validateImportRecord returns the decode error of a record, or else
+// validates it with the binding tags of MovieRequest.
+func validateImportRecord(record ImportRecord) error {
+	if record.Err != nil {
+		return record.Err
+	}
+	return binding.Validator.ValidateStruct(&record.Request)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dostonshernazarov/movies-app/models"
+)
+
+func TestValidateImportRecord(t *testing.T) {
+	valid := models.MovieRequest{Title: "Heat", Director: "Michael Mann", Year: 1995}
+
+	tests := []struct {
+		name   string
+		record ImportRecord
+		want   bool
+	}{
+		{"decode error", ImportRecord{Err: errors.New("invalid JSON")}, false},
+		{"missing title", ImportRecord{Request: models.MovieRequest{Director: "Michael Mann", Year: 1995}}, false},
+		{"missing year", ImportRecord{Request: models.MovieRequest{Title: "Heat", Director: "Michael Mann"}}, false},
+		{"too many genres", ImportRecord{Request: models.MovieRequest{
+			Title: "Heat", Director: "Michael Mann", Year: 1995,
+			Genres: []string{"a", "b", "c", "d", "e", "f"},
+		}}, false},
+		{"valid", ImportRecord{Request: valid}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateImportRecord(tt.record)
+			if got := err == nil; got != tt.want {
+				t.Errorf("validateImportRecord() error = %v, want valid %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestImportRequestsOrdersFailuresByLine only uses records that never reach
+// the database, so it runs without one.
+func TestImportRequestsOrdersFailuresByLine(t *testing.T) {
+	records := []ImportRecord{
+		{Line: 9, Err: errors.New("invalid JSON")},
+		{Line: 2, Request: models.MovieRequest{Title: "Heat"}},
+		{Line: 5, Err: errors.New("invalid year")},
+	}
+
+	s := &MovieService{}
+	imported, failures, err := s.ImportRequests(records, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 0 {
+		t.Errorf("imported = %d, want 0", imported)
+	}
+
+	var lines []int
+	for _, failure := range failures {
+		lines = append(lines, failure.Line)
+	}
+	if want := []int{2, 5, 9}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("failure lines = %v, want %v", lines, want)
+	}
+}
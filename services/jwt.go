@@ -2,10 +2,10 @@ package services
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"time"
 
+	"github.com/dostonshernazarov/movies-app/config"
 	"github.com/dostonshernazarov/movies-app/models"
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -13,22 +13,49 @@ import (
 type JWTService struct {
 	secretKey string
 	issuer    string
+	lifespan  time.Duration
 }
 
-func NewJWTService() *JWTService {
+func NewJWTService(cfg *config.Config) *JWTService {
 	return &JWTService{
-		secretKey: getEnv("JWT_SECRET", "your-secret-key"),
+		secretKey: cfg.Auth.JWTSecret,
 		issuer:    "movies-api",
+		lifespan:  cfg.Auth.AccessTokenLifespan,
 	}
 }
 
-func (s *JWTService) GenerateToken(user models.User) string {
-	expDuration, _ := strconv.Atoi(getEnv("TOKEN_HOUR_LIFESPAN", "24"))
+// Lifespan is how long issued access tokens stay valid.
+func (s *JWTService) Lifespan() time.Duration {
+	return s.lifespan
+}
+
+// GenerateToken issues a short-lived access token. sessionID ties the token
+// to the refresh token family created at login.
+func (s *JWTService) GenerateToken(user models.User, sessionID string) string {
+	return s.GenerateTokenWithLifespan(user, sessionID, s.lifespan)
+}
+
+// GenerateTokenWithLifespan issues an access token that stays valid for
+// lifespan instead of the configured one.
+func (s *JWTService) GenerateTokenWithLifespan(user models.User, sessionID string, lifespan time.Duration) string {
+	tokenID, err := newTokenID()
+	if err != nil {
+		fmt.Println("Error generating token ID:", err)
+		return ""
+	}
+
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"user_id":  user.ID,
 		"username": user.Username,
+		"role":     user.Role,
+		"sid":      sessionID,
+		"jti":      tokenID,
 		"iss":      s.issuer,
-		"exp":      time.Now().Add(time.Hour * time.Duration(expDuration)).Unix(),
+		// Millisecond precision so a session cut-off never spares a token
+		// issued in the same second.
+		"iat": float64(now.UnixMilli()) / 1000,
+		"exp": now.Add(lifespan).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -57,9 +84,72 @@ func (s *JWTService) ExtractUserID(token *jwt.Token) uint {
 	return uint(id)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+func (s *JWTService) ExtractRole(token *jwt.Token) string {
+	claims := token.Claims.(jwt.MapClaims)
+	role, _ := claims["role"].(string)
+	if role == "" {
+		return models.RoleUser
+	}
+	return role
+}
+
+func (s *JWTService) ExtractSessionID(token *jwt.Token) string {
+	claims := token.Claims.(jwt.MapClaims)
+	sessionID, _ := claims["sid"].(string)
+	return sessionID
+}
+
+func (s *JWTService) ExtractTokenID(token *jwt.Token) string {
+	claims := token.Claims.(jwt.MapClaims)
+	tokenID, _ := claims["jti"].(string)
+	return tokenID
+}
+
+func (s *JWTService) ExtractIssuedAt(token *jwt.Token) time.Time {
+	claims := token.Claims.(jwt.MapClaims)
+	iat, _ := claims["iat"].(float64)
+	return time.UnixMilli(int64(iat * 1000))
+}
+
+func (s *JWTService) ExtractExpiresAt(token *jwt.Token) time.Time {
+	claims := token.Claims.(jwt.MapClaims)
+	exp, _ := claims["exp"].(float64)
+	return time.Unix(int64(exp), 0)
+}
+
+// GenerateVerificationToken signs an email verification link token. It uses
+// a key derived from the JWT secret, so it can never pass as an access token.
+// The email is included so that changing the address voids older links.
+func (s *JWTService) GenerateVerificationToken(user models.User, lifespan time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"iss":     s.issuer,
+		"exp":     time.Now().Add(lifespan).Unix(),
 	}
-	return defaultValue
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.verificationKey())
+}
+
+// ValidateVerificationToken returns the user ID and email a verification
+// token was issued for.
+func (s *JWTService) ValidateVerificationToken(tokenString string) (uint, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.verificationKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return 0, "", fmt.Errorf("invalid verification token")
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	email, _ := claims["email"].(string)
+	return s.ExtractUserID(token), email, nil
+}
+
+func (s *JWTService) verificationKey() []byte {
+	return []byte(s.secretKey + ":email-verification")
 }
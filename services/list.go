@@ -0,0 +1,371 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrListNotFound         = errors.New("list not found")
+	ErrListItemExists       = errors.New("movie is already on this list")
+	ErrNotOnList            = errors.New("movie is not on this list")
+	ErrCollaboratorNotFound = errors.New("collaborator not found")
+	ErrOwnerAsCollaborator  = errors.New("the list owner cannot be a collaborator")
+)
+
+type ListService struct {
+	ListRepo  *repositories.ListRepository
+	MovieRepo *repositories.MovieRepository
+	UserRepo  *repositories.UserRepository
+	DB        *gorm.DB
+}
+
+func NewListService(
+	listRepo *repositories.ListRepository,
+	movieRepo *repositories.MovieRepository,
+	userRepo *repositories.UserRepository,
+	db *gorm.DB,
+) *ListService {
+	return &ListService{
+		ListRepo:  listRepo,
+		MovieRepo: movieRepo,
+		UserRepo:  userRepo,
+		DB:        db,
+	}
+}
+
+func (s *ListService) ListPublicLists(page *models.PageQuery) ([]models.List, int64, error) {
+	normalizePage(page)
+	return s.ListRepo.ListPublic(*page)
+}
+
+// ListUserLists returns the lists a user owns or collaborates on.
+func (s *ListService) ListUserLists(userID uint, page *models.PageQuery) ([]models.List, int64, error) {
+	normalizePage(page)
+	return s.ListRepo.ListForUser(userID, *page)
+}
+
+// GetList returns a list the actor may view. Lists the actor cannot see are
+// reported as not found. shareToken grants access to unlisted lists.
+func (s *ListService) GetList(id uint, actor models.Actor, shareToken string) (models.List, error) {
+	list, err := s.ListRepo.GetByID(id)
+	if err != nil {
+		return models.List{}, listLookupError(err)
+	}
+	if err := s.authorizeView(list, actor, shareToken); err != nil {
+		return models.List{}, err
+	}
+	return list, nil
+}
+
+func (s *ListService) ListItems(id uint, actor models.Actor, shareToken string, page *models.PageQuery) ([]models.ListItem, int64, error) {
+	normalizePage(page)
+
+	if _, err := s.GetList(id, actor, shareToken); err != nil {
+		return nil, 0, err
+	}
+	return s.ListRepo.Items(id, *page)
+}
+
+func (s *ListService) CreateList(list *models.List) error {
+	token, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	list.ShareToken = token
+	if list.Visibility == "" {
+		list.Visibility = models.ListPrivate
+	}
+	return s.ListRepo.Create(list)
+}
+
+// UpdateList saves the title, description and visibility of a list. Owners,
+// moderators and admins may update it.
+func (s *ListService) UpdateList(list *models.List, actor models.Actor) error {
+	grant, err := authorizeChange(list.UserID, actor)
+	if err != nil {
+		return err
+	}
+	list.ModifiedByID = actor.UserID
+	list.ModifiedVia = grant
+	return s.ListRepo.Update(list)
+}
+
+// RotateShareToken replaces the share token of a list, so that links handed
+// out before stop working.
+func (s *ListService) RotateShareToken(list *models.List, actor models.Actor) error {
+	if _, err := authorizeChange(list.UserID, actor); err != nil {
+		return err
+	}
+
+	token, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	if err := s.ListRepo.UpdateShareToken(list.ID, token); err != nil {
+		return err
+	}
+	list.ShareToken = token
+	return nil
+}
+
+func (s *ListService) DeleteList(list models.List, actor models.Actor) error {
+	if _, err := authorizeChange(list.UserID, actor); err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		return s.ListRepo.WithTx(tx).Delete(list.ID)
+	})
+}
+
+// AddItem puts a movie on a list at the given position, or at the end when
+// position is zero or past the end.
+func (s *ListService) AddItem(listID uint, item *models.ListItem, actor models.Actor) error {
+	movie, err := s.MovieRepo.GetByID(item.MovieID)
+	if err != nil {
+		return movieLookupError(err)
+	}
+
+	err = s.changeItems(listID, actor, func(lists *repositories.ListRepository, items []models.ListItem) ([]models.ListItem, error) {
+		exists, err := lists.HasItem(listID, item.MovieID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrListItemExists
+		}
+
+		index := clampPosition(item.Position, len(items)+1) - 1
+		item.ListID = listID
+		item.Position = index + 1
+		item.AddedByID = actor.UserID
+		if err := lists.CreateItem(item); err != nil {
+			return nil, err
+		}
+		return insertAt(items, index, *item), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	item.Movie = movie
+	return nil
+}
+
+// UpdateItem replaces the note of a list item and moves it when position is
+// not zero.
+func (s *ListService) UpdateItem(listID, movieID uint, note string, position int, actor models.Actor) (models.ListItem, error) {
+	movie, err := s.MovieRepo.GetByID(movieID)
+	if err != nil {
+		return models.ListItem{}, movieLookupError(err)
+	}
+
+	var item models.ListItem
+	err = s.changeItems(listID, actor, func(lists *repositories.ListRepository, items []models.ListItem) ([]models.ListItem, error) {
+		index := findListItem(items, movieID)
+		if index < 0 {
+			return nil, ErrNotOnList
+		}
+
+		item = items[index]
+		item.Note = note
+		if err := lists.UpdateItemNote(&item); err != nil {
+			return nil, err
+		}
+
+		if position != 0 {
+			target := clampPosition(position, len(items)) - 1
+			items = moveTo(items, index, target)
+			item.Position = target + 1
+		}
+		return items, nil
+	})
+	if err != nil {
+		return models.ListItem{}, err
+	}
+
+	item.Movie = movie
+	return item, nil
+}
+
+// RemoveItem takes a movie off a list. Movies in the trash can't be removed
+// until they are restored.
+func (s *ListService) RemoveItem(listID, movieID uint, actor models.Actor) error {
+	if _, err := s.MovieRepo.GetByID(movieID); err != nil {
+		return movieLookupError(err)
+	}
+
+	return s.changeItems(listID, actor, func(lists *repositories.ListRepository, items []models.ListItem) ([]models.ListItem, error) {
+		index := findListItem(items, movieID)
+		if index < 0 {
+			return nil, ErrNotOnList
+		}
+		if err := lists.DeleteItem(items[index].ID); err != nil {
+			return nil, err
+		}
+		return append(items[:index], items[index+1:]...), nil
+	})
+}
+
+// SaveCollaborator gives a user editor or viewer rights on a list, or
+// changes the rights they already have. Owners, moderators and admins may
+// manage collaborators.
+func (s *ListService) SaveCollaborator(list models.List, collaborator *models.ListCollaborator, actor models.Actor) error {
+	if _, err := authorizeChange(list.UserID, actor); err != nil {
+		return err
+	}
+	if collaborator.UserID == list.UserID {
+		return ErrOwnerAsCollaborator
+	}
+
+	user, err := s.UserRepo.FindByID(collaborator.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	collaborator.ListID = list.ID
+	if err := s.ListRepo.SaveCollaborator(collaborator); err != nil {
+		return err
+	}
+	collaborator.User = user
+	return nil
+}
+
+// RemoveCollaborator revokes a user's rights on a list. Besides the owner,
+// moderators and admins, collaborators may remove themselves.
+func (s *ListService) RemoveCollaborator(list models.List, userID uint, actor models.Actor) error {
+	if actor.UserID != userID {
+		if _, err := authorizeChange(list.UserID, actor); err != nil {
+			return err
+		}
+	}
+
+	removed, err := s.ListRepo.DeleteCollaborator(list.ID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrCollaboratorNotFound
+	}
+	return nil
+}
+
+// changeItems runs change on the ordered items of a list with the list row
+// locked, then stores the resulting order and records who made the change.
+func (s *ListService) changeItems(
+	listID uint,
+	actor models.Actor,
+	change func(lists *repositories.ListRepository, items []models.ListItem) ([]models.ListItem, error),
+) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		lists := s.ListRepo.WithTx(tx)
+		list, err := lists.GetForUpdate(listID)
+		if err != nil {
+			return listLookupError(err)
+		}
+
+		grant, err := s.authorizeEdit(lists, list, actor)
+		if err != nil {
+			return err
+		}
+
+		items, err := lists.AllItems(listID)
+		if err != nil {
+			return err
+		}
+		items, err = change(lists, items)
+		if err != nil {
+			return err
+		}
+
+		for i, item := range items {
+			if item.Position == i+1 {
+				continue
+			}
+			if err := lists.UpdateItemPosition(item.ID, i+1); err != nil {
+				return err
+			}
+		}
+
+		list.ModifiedByID = actor.UserID
+		list.ModifiedVia = grant
+		return lists.Touch(&list)
+	})
+}
+
+// authorizeView allows owners, collaborators, moderators and admins to see
+// any list, everyone to see public lists, and holders of the share token to
+// see unlisted lists.
+func (s *ListService) authorizeView(list models.List, actor models.Actor, shareToken string) error {
+	if _, err := authorizeChange(list.UserID, actor); err == nil {
+		return nil
+	}
+
+	switch list.Visibility {
+	case models.ListPublic:
+		return nil
+	case models.ListUnlisted:
+		if shareToken != "" && subtle.ConstantTimeCompare([]byte(shareToken), []byte(list.ShareToken)) == 1 {
+			return nil
+		}
+	}
+
+	for _, collaborator := range list.Collaborators {
+		if collaborator.UserID == actor.UserID {
+			return nil
+		}
+	}
+	return ErrListNotFound
+}
+
+// authorizeEdit reports which grant allows the actor to change the items of
+// a list: the owner, moderators and admins, then editor collaborators.
+// Actors who may not even see the list get ErrListNotFound.
+func (s *ListService) authorizeEdit(lists *repositories.ListRepository, list models.List, actor models.Actor) (string, error) {
+	if grant, err := authorizeChange(list.UserID, actor); err == nil {
+		return grant, nil
+	}
+
+	collaborator, err := lists.GetCollaborator(list.ID, actor.UserID)
+	switch {
+	case err == nil && collaborator.Role == models.ListEditor:
+		return models.ListEditor, nil
+	case err == nil || list.Visibility == models.ListPublic:
+		return "", ErrForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "", ErrListNotFound
+	default:
+		return "", err
+	}
+}
+
+// CanManage reports whether the actor may change the settings and
+// collaborators of a list and see its share token.
+func (s *ListService) CanManage(list models.List, actor models.Actor) bool {
+	_, err := authorizeChange(list.UserID, actor)
+	return err == nil
+}
+
+func findListItem(items []models.ListItem, movieID uint) int {
+	for i, item := range items {
+		if item.MovieID == movieID {
+			return i
+		}
+	}
+	return -1
+}
+
+func listLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrListNotFound
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/config"
+)
+
+// Mailer delivers plain-text email.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer picks the implementation named by mail.driver: "smtp" for a real
+// server, "log" writes messages to mail.log_file or the process log.
+func NewMailer(cfg *config.Config) Mailer {
+	if cfg.Mail.Driver == "smtp" {
+		return &SMTPMailer{
+			Host:     cfg.Mail.SMTP.Host,
+			Port:     cfg.Mail.SMTP.Port,
+			Username: cfg.Mail.SMTP.Username,
+			Password: cfg.Mail.SMTP.Password,
+			From:     cfg.Mail.From,
+		}
+	}
+	return &LogMailer{Path: cfg.Mail.LogFile}
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{to}, []byte(msg))
+}
+
+func (m *SMTPMailer) Name() string {
+	return "mail"
+}
+
+// Check makes the mailer a HealthChecker; it connects to the server.
+func (m *SMTPMailer) Check(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.Host, m.Port))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// LogMailer records messages instead of sending them, for development and
+// tests. With an empty Path it writes to the standard logger.
+type LogMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	entry := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n---\n", to, subject, body)
+
+	if m.Path == "" {
+		log.Print("mail: " + entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry)
+	return err
+}
@@ -1,25 +1,110 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dostonshernazarov/movies-app/config"
 	"github.com/dostonshernazarov/movies-app/models"
 	"github.com/dostonshernazarov/movies-app/repositories"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidSort     = errors.New("invalid sort field")
+	ErrEmptySearch     = errors.New("search query has no searchable terms")
+	ErrForbidden       = errors.New("permission denied")
+	ErrMovieNotFound   = errors.New("movie not found")
+	ErrVersionConflict = errors.New("movie has been modified since it was read")
+)
+
+const purgeBatchSize = 100
+
+var movieSortColumns = map[string]string{
+	"id":             "id",
+	"title":          "title",
+	"director":       "director",
+	"year":           "year",
+	"genre":          "genre",
+	"rating":         "average_rating",
+	"average_rating": "average_rating",
+	"vote_count":     "vote_count",
+	"created_at":     "created_at",
+	"updated_at":     "updated_at",
+}
+
 type MovieService struct {
-	MovieRepo *repositories.MovieRepository
-	DB        *gorm.DB
+	MovieRepo    *repositories.MovieRepository
+	GenreRepo    *repositories.GenreRepository
+	PersonRepo   *repositories.PersonRepository
+	RevisionRepo *repositories.RevisionRepository
+	Store        BlobStore
+	DB           *gorm.DB
 }
 
-func NewMovieService(movieRepo *repositories.MovieRepository, db *gorm.DB) *MovieService {
+func NewMovieService(
+	movieRepo *repositories.MovieRepository,
+	genreRepo *repositories.GenreRepository,
+	personRepo *repositories.PersonRepository,
+	revisionRepo *repositories.RevisionRepository,
+	store BlobStore,
+	db *gorm.DB,
+) *MovieService {
 	return &MovieService{
-		MovieRepo: movieRepo,
-		DB:        db,
+		MovieRepo:    movieRepo,
+		GenreRepo:    genreRepo,
+		PersonRepo:   personRepo,
+		RevisionRepo: revisionRepo,
+		Store:        store,
+		DB:           db,
+	}
+}
+
+func (s *MovieService) ListMovies(query *models.MovieListQuery) ([]models.Movie, int64, error) {
+	normalizePage(&query.PageQuery)
+	genre, err := genreFilter(query.Genre)
+	if err != nil {
+		return nil, 0, err
+	}
+	query.Genre = genre
+
+	order, err := parseMovieSort(query.Sort)
+	if err != nil {
+		return nil, 0, err
 	}
+
+	return s.MovieRepo.List(*query, order)
 }
 
-func (s *MovieService) GetAllMovies() ([]models.Movie, error) {
-	return s.MovieRepo.GetAll()
+// ExportMovies calls fn for every movie matching the listing filters, in
+// the requested order, streaming them from the database with their Genres.
+func (s *MovieService) ExportMovies(query *models.MovieListQuery, fn func(models.Movie) error) error {
+	genre, err := genreFilter(query.Genre)
+	if err != nil {
+		return err
+	}
+	query.Genre = genre
+
+	order, err := parseMovieSort(query.Sort)
+	if err != nil {
+		return err
+	}
+
+	return s.MovieRepo.Stream(*query, order, fn)
+}
+
+func (s *MovieService) SearchMovies(query *models.MovieSearchQuery) ([]models.MovieSearchHit, int64, error) {
+	normalizePage(&query.PageQuery)
+
+	tsQuery := buildTSQuery(query.Q)
+	if tsQuery == "" {
+		return nil, 0, ErrEmptySearch
+	}
+
+	return s.MovieRepo.Search(tsQuery, query.PageQuery)
 }
 
 func (s *MovieService) GetMovieByID(id uint) (models.Movie, error) {
@@ -28,22 +113,447 @@ func (s *MovieService) GetMovieByID(id uint) (models.Movie, error) {
 
 func (s *MovieService) CreateMovie(movie *models.Movie) error {
 	return s.DB.Transaction(func(tx *gorm.DB) error {
-		return s.MovieRepo.Create(movie)
+		return s.createMovie(tx, movie)
 	})
 }
 
-func (s *MovieService) UpdateMovie(movie *models.Movie) error {
+func (s *MovieService) createMovie(tx *gorm.DB, movie *models.Movie) error {
+	movie.Version = 1
+	if err := s.resolveGenres(tx, movie); err != nil {
+		return err
+	}
+	if err := s.MovieRepo.WithTx(tx).Create(movie); err != nil {
+		return err
+	}
+	if err := s.syncDirectors(tx, movie, "", movie.UserID); err != nil {
+		return err
+	}
+	return s.recordRevision(tx, movie, models.RevisionCreate, movie.UserID, nil)
+}
+
+func (s *MovieService) UpdateMovie(movie *models.Movie, actor models.Actor) error {
+	grant, err := authorizeChange(movie.UserID, actor)
+	if err != nil {
+		return err
+	}
+
+	movie.ModifiedByID = actor.UserID
+	movie.ModifiedVia = grant
+
 	return s.DB.Transaction(func(tx *gorm.DB) error {
-		return s.MovieRepo.Update(movie)
+		repo := s.MovieRepo.WithTx(tx)
+		current, err := repo.GetForUpdate(movie.ID)
+		if err != nil {
+			return movieLookupError(err)
+		}
+		if current.Version != movie.Version {
+			return ErrVersionConflict
+		}
+
+		if err := s.resolveGenres(tx, movie); err != nil {
+			return err
+		}
+		movie.Version++
+		if err := repo.Update(movie); err != nil {
+			return err
+		}
+		if current.Director != movie.Director {
+			if err := s.syncDirectors(tx, movie, current.Director, actor.UserID); err != nil {
+				return err
+			}
+		}
+		return s.recordRevision(tx, movie, models.RevisionUpdate, actor.UserID, nil)
 	})
 }
 
-func (s *MovieService) DeleteMovie(id uint) error {
+func (s *MovieService) DeleteMovie(movie *models.Movie, actor models.Actor) error {
+	grant, err := authorizeChange(movie.UserID, actor)
+	if err != nil {
+		return err
+	}
+
+	movie.ModifiedByID = actor.UserID
+	movie.ModifiedVia = grant
+
 	return s.DB.Transaction(func(tx *gorm.DB) error {
-		return s.MovieRepo.Delete(id)
+		repo := s.MovieRepo.WithTx(tx)
+		current, err := repo.GetForUpdate(movie.ID)
+		if err != nil {
+			return movieLookupError(err)
+		}
+		if current.Version != movie.Version {
+			return ErrVersionConflict
+		}
+
+		movie.Version++
+		if err := repo.UpdateColumns(movie, []string{"modified_by_id", "modified_via", "version"}); err != nil {
+			return err
+		}
+		if err := repo.Delete(movie.ID); err != nil {
+			return err
+		}
+		return s.recordRevision(tx, movie, models.RevisionDelete, actor.UserID, nil)
+	})
+}
+
+// ListTrash returns a page of the user's soft-deleted movies.
+func (s *MovieService) ListTrash(userID uint, page *models.PageQuery) ([]models.Movie, int64, error) {
+	normalizePage(page)
+	return s.MovieRepo.ListDeleted(userID, *page)
+}
+
+// RestoreMovie brings a soft-deleted movie back. Owners may restore movies
+// they deleted themselves; a movie removed by a moderator or admin can only
+// be restored by one.
+func (s *MovieService) RestoreMovie(id uint, actor models.Actor) (models.Movie, error) {
+	var movie models.Movie
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		repo := s.MovieRepo.WithTx(tx)
+		var err error
+		movie, err = repo.GetDeletedForUpdate(id)
+		if err != nil {
+			return movieLookupError(err)
+		}
+
+		grant, err := authorizeChange(movie.UserID, actor)
+		if err != nil {
+			return err
+		}
+		if grant == "owner" && (movie.ModifiedVia == models.RoleModerator || movie.ModifiedVia == models.RoleAdmin) {
+			return ErrForbidden
+		}
+
+		movie.ModifiedByID = actor.UserID
+		movie.ModifiedVia = grant
+		movie.Version++
+		if err := repo.Restore(&movie); err != nil {
+			return err
+		}
+		return s.recordRevision(tx, &movie, models.RevisionRestore, actor.UserID, nil)
+	})
+	return movie, err
+}
+
+// PurgeMovie permanently deletes a movie, in the trash or not, with its
+// reviews, credits, list entries, history and images.
+func (s *MovieService) PurgeMovie(id uint) error {
+	return s.purgeMovie(id, nil)
+}
+
+// purgeMovie permanently deletes a movie. With a cutoff, only a movie still
+// in the trash since before it is deleted; otherwise ErrMovieNotFound is
+// returned.
+func (s *MovieService) purgeMovie(id uint, cutoff *time.Time) error {
+	var movie models.Movie
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		repo := s.MovieRepo.WithTx(tx)
+		var err error
+		if movie, err = repo.GetAnyForUpdate(id); err != nil {
+			return movieLookupError(err)
+		}
+		if cutoff != nil && (!movie.DeletedAt.Valid || !movie.DeletedAt.Time.Before(*cutoff)) {
+			return ErrMovieNotFound
+		}
+		return repo.HardDelete(id)
 	})
+	if err != nil {
+		return err
+	}
+
+	deleteImageObjects(s.Store, movie.Poster, movie.Backdrop)
+	return nil
+}
+
+// PurgeTrash permanently deletes movies that have been in the trash since
+// before cutoff and returns how many were removed.
+func (s *MovieService) PurgeTrash(cutoff time.Time) (int, error) {
+	purged := 0
+	for {
+		ids, err := s.MovieRepo.DeletedBefore(cutoff, purgeBatchSize)
+		if err != nil || len(ids) == 0 {
+			return purged, err
+		}
+
+		for _, id := range ids {
+			err := s.purgeMovie(id, &cutoff)
+			switch {
+			case err == nil:
+				purged++
+			case errors.Is(err, ErrMovieNotFound):
+				// Purged or restored since the batch was read.
+			default:
+				return purged, err
+			}
+		}
+	}
+}
+
+func (s *MovieService) GetUserMovies(userID uint, query *models.MovieListQuery) ([]models.Movie, int64, error) {
+	query.UserID = userID
+	return s.ListMovies(query)
 }
 
-func (s *MovieService) GetUserMovies(userID uint) ([]models.Movie, error) {
-	return s.MovieRepo.FindByUserID(userID)
+func (s *MovieService) GetUserStats(userID uint) (models.UserMovieStats, error) {
+	return s.MovieRepo.GetUserStats(userID, config.TopGenresCount)
+}
+
+// PatchMovie saves the editable fields of movie that differ from the stored
+// row, leaving every other column untouched. Nothing is written when no
+// field changed.
+func (s *MovieService) PatchMovie(movie *models.Movie, actor models.Actor) error {
+	return s.patch(movie, actor, models.RevisionUpdate, nil)
+}
+
+// RevertMovie restores the editable fields of movie to their values at an
+// earlier revision. It needs the same rights as an update and is recorded
+// as a new revision.
+func (s *MovieService) RevertMovie(movie *models.Movie, revision int, actor models.Actor) error {
+	rev, err := s.RevisionRepo.Get(movie.ID, revision)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRevisionNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	snapshot := rev.Snapshot
+	movie.Title = snapshot.Title
+	movie.Director = snapshot.Director
+	movie.Year = snapshot.Year
+	movie.Plot = snapshot.Plot
+	movie.Genres = make([]models.Genre, len(snapshot.Genres))
+	for i, name := range snapshot.Genres {
+		movie.Genres[i] = models.Genre{Name: name}
+	}
+
+	return s.patch(movie, actor, models.RevisionRevert, &revision)
+}
+
+func (s *MovieService) patch(movie *models.Movie, actor models.Actor, action string, revertedFrom *int) error {
+	grant, err := authorizeChange(movie.UserID, actor)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		repo := s.MovieRepo.WithTx(tx)
+		if _, err := repo.GetForUpdate(movie.ID); err != nil {
+			return movieLookupError(err)
+		}
+		current, err := repo.GetByID(movie.ID)
+		if err != nil {
+			return movieLookupError(err)
+		}
+		if current.Version != movie.Version {
+			return ErrVersionConflict
+		}
+
+		columns := changedMovieColumns(current, *movie)
+		genresChanged := !sameGenres(current.Genres, canonicalGenres(movie.Genres))
+		if genresChanged {
+			if err := s.resolveGenres(tx, movie); err != nil {
+				return err
+			}
+			columns = append(columns, "genre")
+		} else {
+			movie.Genres = current.Genres
+			movie.Genre = current.Genre
+		}
+		if len(columns) == 0 {
+			return nil
+		}
+
+		movie.ModifiedByID = actor.UserID
+		movie.ModifiedVia = grant
+		movie.Version++
+		columns = append(columns, "modified_by_id", "modified_via", "version", "updated_at")
+		if err := repo.UpdateColumns(movie, columns); err != nil {
+			return err
+		}
+
+		if genresChanged {
+			if err := repo.ReplaceGenres(movie); err != nil {
+				return err
+			}
+		}
+		if current.Director != movie.Director {
+			if err := s.syncDirectors(tx, movie, current.Director, actor.UserID); err != nil {
+				return err
+			}
+		}
+		return s.recordRevision(tx, movie, action, actor.UserID, revertedFrom)
+	})
+}
+
+// changedMovieColumns lists the editable scalar columns whose values differ
+// between two versions of a movie.
+func changedMovieColumns(current, updated models.Movie) []string {
+	var columns []string
+	if current.Title != updated.Title {
+		columns = append(columns, "title")
+	}
+	if current.Director != updated.Director {
+		columns = append(columns, "director")
+	}
+	if current.Year != updated.Year {
+		columns = append(columns, "year")
+	}
+	if current.Plot != updated.Plot {
+		columns = append(columns, "plot")
+	}
+	return columns
+}
+
+// sameGenres reports whether genres and the canonical genres hold the same
+// set of slugs.
+func sameGenres(genres, canonical []models.Genre) bool {
+	if len(genres) != len(canonical) {
+		return false
+	}
+
+	slugs := make(map[string]bool, len(genres))
+	for _, genre := range genres {
+		slugs[genre.Slug] = true
+	}
+	for _, genre := range canonical {
+		if !slugs[genre.Slug] {
+			return false
+		}
+	}
+	return true
+}
+
+// recordRevision appends a snapshot of movie to its history.
+func (s *MovieService) recordRevision(tx *gorm.DB, movie *models.Movie, action string, editorID uint, revertedFrom *int) error {
+	return s.RevisionRepo.WithTx(tx).Append(&models.MovieRevision{
+		MovieID:      movie.ID,
+		Action:       action,
+		Snapshot:     snapshotMovie(*movie),
+		EditorID:     editorID,
+		RevertedFrom: revertedFrom,
+	})
+}
+
+// resolveGenres replaces the free-text genres of a movie with canonical,
+// stored genres and refreshes the denormalised genre column.
+func (s *MovieService) resolveGenres(tx *gorm.DB, movie *models.Movie) error {
+	genres, err := s.GenreRepo.WithTx(tx).FindOrCreate(canonicalGenres(movie.Genres))
+	if err != nil {
+		return err
+	}
+
+	movie.Genres = genres
+	movie.Genre = genreNames(genres)
+	return nil
+}
+
+// syncDirectors reconciles the director credits that come from the movie's
+// Director string after it changed from previous: people no longer named
+// lose their credit and newly named people gain one, creating person
+// records as needed. Director credits added through the credits API are
+// left alone.
+func (s *MovieService) syncDirectors(tx *gorm.DB, movie *models.Movie, previous string, actorID uint) error {
+	people := s.PersonRepo.WithTx(tx)
+
+	names := directorNames(movie.Director)
+	if err := people.RemoveDirectors(movie.ID, removedNames(directorNames(previous), names)); err != nil {
+		return err
+	}
+
+	var directors []models.Person
+	for _, name := range names {
+		person, err := people.FindOrCreateByName(name, actorID)
+		if err != nil {
+			return err
+		}
+		directors = append(directors, person)
+	}
+	return people.AddDirectors(movie.ID, directors)
+}
+
+// authorizeChange reports which grant allows the actor to modify a resource
+// owned by ownerID: ownership first, then a moderator or admin role.
+func authorizeChange(ownerID uint, actor models.Actor) (string, error) {
+	if ownerID == actor.UserID {
+		return "owner", nil
+	}
+
+	switch actor.Role {
+	case models.RoleAdmin, models.RoleModerator:
+		return actor.Role, nil
+	}
+
+	return "", ErrForbidden
+}
+
+func normalizePage(page *models.PageQuery) {
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.Limit < 1 {
+		page.Limit = config.DefaultPageSize
+	}
+	if page.Limit > config.MaxPageSize {
+		page.Limit = config.MaxPageSize
+	}
+}
+
+// parseMovieSort turns a sort expression such as "-rating,year" into an
+// ORDER BY clause. The id column is always appended so pages stay stable.
+func parseMovieSort(sort string) (string, error) {
+	var clauses []string
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+			field = field[1:]
+		}
+
+		column, ok := movieSortColumns[field]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrInvalidSort, field)
+		}
+		clauses = append(clauses, column+" "+direction)
+	}
+
+	return strings.Join(append(clauses, "id ASC"), ", "), nil
+}
+
+// buildTSQuery converts user input into a to_tsquery expression. Quoted text
+// becomes a phrase query, a trailing * turns a word into a prefix match and
+// all terms must match. Everything except letters and digits is dropped so
+// the result is always valid tsquery syntax.
+func buildTSQuery(input string) string {
+	var terms []string
+	for i, part := range strings.Split(input, `"`) {
+		if i%2 == 1 {
+			if words := searchWords(part); len(words) > 0 {
+				terms = append(terms, "("+strings.Join(words, " <-> ")+")")
+			}
+			continue
+		}
+
+		for _, field := range strings.Fields(part) {
+			words := searchWords(field)
+			if len(words) == 0 {
+				continue
+			}
+			if strings.HasSuffix(field, "*") {
+				words[len(words)-1] += ":*"
+			}
+			terms = append(terms, words...)
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
 }
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMovieSort(t *testing.T) {
+	tests := []struct {
+		sort    string
+		want    string
+		wantErr bool
+	}{
+		{sort: "", want: "id ASC"},
+		{sort: "title", want: "title ASC, id ASC"},
+		{sort: "-year", want: "year DESC, id ASC"},
+		{sort: "-rating,year", want: "average_rating DESC, year ASC, id ASC"},
+		{sort: "-average_rating,-vote_count", want: "average_rating DESC, vote_count DESC, id ASC"},
+		{sort: " -year , title ", want: "year DESC, title ASC, id ASC"},
+		{sort: "title,,year,", want: "title ASC, year ASC, id ASC"},
+		{sort: "plot", wantErr: true},
+		{sort: "-", wantErr: true},
+		{sort: "--year", wantErr: true},
+		{sort: "year;DROP TABLE movies", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			got, err := parseMovieSort(tt.sort)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSort) {
+					t.Fatalf("parseMovieSort(%q) error = %v, want ErrInvalidSort", tt.sort, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMovieSort(%q) error = %v", tt.sort, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseMovieSort(%q) = %q, want %q", tt.sort, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"Matrix", "matrix"},
+		{"the matrix", "the & matrix"},
+		{`"dark knight"`, "(dark <-> knight)"},
+		{`"dark knight`, "(dark <-> knight)"},
+		{`""`, ""},
+		{"bat*", "bat:*"},
+		{"*", ""},
+		{"spider-man", "spider & man"},
+		{"spider-man*", "spider & man:*"},
+		{`godfather "part ii" 197*`, "godfather & (part <-> ii) & 197:*"},
+		{"Amélie", "amélie"},
+		{"a & b | !c <-> d:*", "a & b & c & d:*"},
+		{"'); DROP TABLE movies; --", "drop & table & movies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := buildTSQuery(tt.input); got != tt.want {
+				t.Errorf("buildTSQuery(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
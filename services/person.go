@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPersonNotFound = errors.New("person not found")
+	ErrCreditNotFound = errors.New("credit not found")
+	ErrPersonExists   = errors.New("a person with this name already exists")
+)
+
+type PersonService struct {
+	PersonRepo *repositories.PersonRepository
+	MovieRepo  *repositories.MovieRepository
+	DB         *gorm.DB
+}
+
+func NewPersonService(personRepo *repositories.PersonRepository, movieRepo *repositories.MovieRepository, db *gorm.DB) *PersonService {
+	return &PersonService{
+		PersonRepo: personRepo,
+		MovieRepo:  movieRepo,
+		DB:         db,
+	}
+}
+
+func (s *PersonService) ListPeople(query *models.PersonListQuery) ([]models.Person, int64, error) {
+	normalizePage(&query.PageQuery)
+	return s.PersonRepo.List(strings.TrimSpace(query.Q), query.PageQuery)
+}
+
+func (s *PersonService) GetPerson(id uint) (models.Person, error) {
+	person, err := s.PersonRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Person{}, ErrPersonNotFound
+	}
+	return person, err
+}
+
+func (s *PersonService) CreatePerson(person *models.Person) error {
+	return personWriteError(s.PersonRepo.Create(person))
+}
+
+func (s *PersonService) UpdatePerson(person *models.Person, actor models.Actor) error {
+	if _, err := authorizeChange(person.CreatedByID, actor); err != nil {
+		return err
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		return s.PersonRepo.WithTx(tx).Update(person)
+	})
+	return personWriteError(err)
+}
+
+func personWriteError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrPersonExists
+	}
+	return err
+}
+
+func (s *PersonService) DeletePerson(person models.Person, actor models.Actor) error {
+	if _, err := authorizeChange(person.CreatedByID, actor); err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		return s.PersonRepo.WithTx(tx).Delete(person.ID)
+	})
+}
+
+func (s *PersonService) GetFilmography(id uint) (models.Person, []models.Credit, error) {
+	person, err := s.GetPerson(id)
+	if err != nil {
+		return models.Person{}, nil, err
+	}
+
+	credits, err := s.PersonRepo.Filmography(id)
+	return person, credits, err
+}
+
+func (s *PersonService) GetMovieCredits(movieID uint) ([]models.Credit, error) {
+	if _, err := s.MovieRepo.GetByID(movieID); err != nil {
+		return nil, movieLookupError(err)
+	}
+	return s.PersonRepo.MovieCredits(movieID)
+}
+
+// AddCredit credits a person on a movie. The actor needs the same rights as
+// for updating the movie.
+func (s *PersonService) AddCredit(credit *models.Credit, actor models.Actor) error {
+	movie, err := s.MovieRepo.GetByID(credit.MovieID)
+	if err != nil {
+		return movieLookupError(err)
+	}
+	if _, err := authorizeChange(movie.UserID, actor); err != nil {
+		return err
+	}
+
+	person, err := s.GetPerson(credit.PersonID)
+	if err != nil {
+		return err
+	}
+
+	if credit.Role != models.CreditActor {
+		credit.Character = ""
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.PersonRepo.WithTx(tx).CreateCredit(credit); err != nil {
+			return err
+		}
+		return s.MovieRepo.WithTx(tx).BumpVersion(credit.MovieID)
+	})
+	if err != nil {
+		return err
+	}
+	credit.Person = person
+	return nil
+}
+
+func (s *PersonService) DeleteCredit(movieID, creditID uint, actor models.Actor) error {
+	movie, err := s.MovieRepo.GetByID(movieID)
+	if err != nil {
+		return movieLookupError(err)
+	}
+	if _, err := authorizeChange(movie.UserID, actor); err != nil {
+		return err
+	}
+
+	if _, err := s.PersonRepo.GetCredit(movieID, creditID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCreditNotFound
+		}
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.PersonRepo.WithTx(tx).DeleteCredit(creditID); err != nil {
+			return err
+		}
+		return s.MovieRepo.WithTx(tx).BumpVersion(movieID)
+	})
+}
+
+// removedNames returns the names in previous that are not in names,
+// ignoring case.
+func removedNames(previous, names []string) []string {
+	kept := make(map[string]bool, len(names))
+	for _, name := range names {
+		kept[strings.ToLower(name)] = true
+	}
+
+	var removed []string
+	for _, name := range previous {
+		if !kept[strings.ToLower(name)] {
+			removed = append(removed, name)
+		}
+	}
+	return removed
+}
+
+// directorNames splits a director string such as "Joel Coen & Ethan Coen"
+// into individual names.
+func directorNames(director string) []string {
+	var names []string
+	for _, name := range strings.FieldsFunc(director, func(r rune) bool {
+		return r == ',' || r == '&' || r == ';' || r == '/'
+	}) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,25 @@
+package services
+
+// clampPosition limits a 1-based position to [1, last]. Zero means last.
+func clampPosition(position, last int) int {
+	if position < 1 || position > last {
+		return last
+	}
+	return position
+}
+
+// insertAt inserts item at index, shifting the following items up.
+func insertAt[T any](items []T, index int, item T) []T {
+	var zero T
+	items = append(items, zero)
+	copy(items[index+1:], items[index:])
+	items[index] = item
+	return items
+}
+
+// moveTo moves the item at from to index to, shifting the items in between.
+func moveTo[T any](items []T, from, to int) []T {
+	item := items[from]
+	items = append(items[:from], items[from+1:]...)
+	return insertAt(items, to, item)
+}
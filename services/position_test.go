@@ -0,0 +1,78 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClampPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		position int
+		last     int
+		want     int
+	}{
+		{"first", 1, 3, 1},
+		{"middle", 2, 3, 2},
+		{"last", 3, 3, 3},
+		{"zero means last", 0, 3, 3},
+		{"negative", -1, 3, 3},
+		{"past the end", 4, 3, 3},
+		{"single item", 1, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clampPosition(tt.position, tt.last); got != tt.want {
+				t.Errorf("clampPosition(%d, %d) = %d, want %d", tt.position, tt.last, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertAt(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []string
+		index int
+		want  []string
+	}{
+		{"into empty", nil, 0, []string{"x"}},
+		{"at start", []string{"a", "b"}, 0, []string{"x", "a", "b"}},
+		{"in middle", []string{"a", "b"}, 1, []string{"a", "x", "b"}},
+		{"at end", []string{"a", "b"}, 2, []string{"a", "b", "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := append([]string(nil), tt.items...)
+			if got := insertAt(items, tt.index, "x"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("insertAt(%q, %d) = %q, want %q", tt.items, tt.index, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoveTo(t *testing.T) {
+	tests := []struct {
+		name string
+		from int
+		to   int
+		want []string
+	}{
+		{"in place", 1, 1, []string{"a", "b", "c", "d"}},
+		{"first to last", 0, 3, []string{"b", "c", "d", "a"}},
+		{"last to first", 3, 0, []string{"d", "a", "b", "c"}},
+		{"down one", 1, 2, []string{"a", "c", "b", "d"}},
+		{"up one", 2, 1, []string{"a", "c", "b", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := []string{"a", "b", "c", "d"}
+			if got := moveTo(items, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("moveTo(%d, %d) = %q, want %q", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package services
+
+import "sync/atomic"
+
+// Readiness tells load balancers whether the instance should receive
+// traffic. It starts ready and stops being ready for good once the server
+// begins shutting down.
+type Readiness struct {
+	draining atomic.Bool
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// Drain marks the instance as shutting down.
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+// Draining reports whether Drain has been called.
+func (r *Readiness) Draining() bool {
+	return r.draining.Load()
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReviewNotFound = errors.New("review not found")
+	ErrReviewExists   = errors.New("you have already reviewed this movie")
+)
+
+type ReviewService struct {
+	ReviewRepo *repositories.ReviewRepository
+	MovieRepo  *repositories.MovieRepository
+	DB         *gorm.DB
+}
+
+func NewReviewService(reviewRepo *repositories.ReviewRepository, movieRepo *repositories.MovieRepository, db *gorm.DB) *ReviewService {
+	return &ReviewService{
+		ReviewRepo: reviewRepo,
+		MovieRepo:  movieRepo,
+		DB:         db,
+	}
+}
+
+func (s *ReviewService) ListReviews(movieID uint, page *models.PageQuery) ([]models.Review, int64, error) {
+	normalizePage(page)
+
+	if _, err := s.MovieRepo.GetByID(movieID); err != nil {
+		return nil, 0, movieLookupError(err)
+	}
+	return s.ReviewRepo.ListByMovie(movieID, *page)
+}
+
+func (s *ReviewService) GetReview(movieID, id uint) (models.Review, error) {
+	review, err := s.ReviewRepo.GetByID(movieID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Review{}, ErrReviewNotFound
+	}
+	return review, err
+}
+
+func (s *ReviewService) CreateReview(review *models.Review) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		// Locking the movie serialises review changes so the cached
+		// average never misses a concurrent write.
+		if _, err := s.MovieRepo.WithTx(tx).GetForUpdate(review.MovieID); err != nil {
+			return movieLookupError(err)
+		}
+
+		reviews := s.ReviewRepo.WithTx(tx)
+		exists, err := reviews.ExistsForUser(review.MovieID, review.UserID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrReviewExists
+		}
+
+		if err := reviews.Create(review); err != nil {
+			return err
+		}
+		return reviews.RefreshMovieRating(review.MovieID)
+	})
+}
+
+func (s *ReviewService) UpdateReview(review *models.Review, actor models.Actor) error {
+	if _, err := authorizeChange(review.UserID, actor); err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.MovieRepo.WithTx(tx).GetForUpdate(review.MovieID); err != nil {
+			return movieLookupError(err)
+		}
+
+		reviews := s.ReviewRepo.WithTx(tx)
+		if err := reviews.Update(review); err != nil {
+			return err
+		}
+		return reviews.RefreshMovieRating(review.MovieID)
+	})
+}
+
+func (s *ReviewService) DeleteReview(review models.Review, actor models.Actor) error {
+	if _, err := authorizeChange(review.UserID, actor); err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.MovieRepo.WithTx(tx).GetForUpdate(review.MovieID); err != nil {
+			return movieLookupError(err)
+		}
+
+		reviews := s.ReviewRepo.WithTx(tx)
+		if err := reviews.Delete(review.ID); err != nil {
+			return err
+		}
+		return reviews.RefreshMovieRating(review.MovieID)
+	})
+}
+
+func movieLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMovieNotFound
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/repositories"
+	"gorm.io/gorm"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+type RevisionService struct {
+	RevisionRepo *repositories.RevisionRepository
+	MovieRepo    *repositories.MovieRepository
+}
+
+func NewRevisionService(revisionRepo *repositories.RevisionRepository, movieRepo *repositories.MovieRepository) *RevisionService {
+	return &RevisionService{
+		RevisionRepo: revisionRepo,
+		MovieRepo:    movieRepo,
+	}
+}
+
+func (s *RevisionService) ListRevisions(movieID uint, page *models.PageQuery) ([]models.MovieRevision, int64, error) {
+	normalizePage(page)
+
+	if _, err := s.MovieRepo.GetByID(movieID); err != nil {
+		return nil, 0, movieLookupError(err)
+	}
+	return s.RevisionRepo.List(movieID, *page)
+}
+
+func (s *RevisionService) GetRevision(movieID uint, revision int) (models.MovieRevision, error) {
+	if _, err := s.MovieRepo.GetByID(movieID); err != nil {
+		return models.MovieRevision{}, movieLookupError(err)
+	}
+
+	rev, err := s.RevisionRepo.Get(movieID, revision)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.MovieRevision{}, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// DiffRevisions lists the snapshot fields that differ between two revisions
+// of a movie, in snapshot field order.
+func (s *RevisionService) DiffRevisions(movieID uint, from, to int) ([]models.FieldChange, error) {
+	fromRev, err := s.GetRevision(movieID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := s.RevisionRepo.Get(movieID, to)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return diffSnapshots(fromRev.Snapshot, toRev.Snapshot), nil
+}
+
+func diffSnapshots(from, to models.MovieSnapshot) []models.FieldChange {
+	changes := []models.FieldChange{}
+	fromValue := reflect.ValueOf(from)
+	toValue := reflect.ValueOf(to)
+	for i := 0; i < fromValue.NumField(); i++ {
+		a, b := fromValue.Field(i).Interface(), toValue.Field(i).Interface()
+		if sameValue(a, b) {
+			continue
+		}
+
+		field := strings.Split(fromValue.Type().Field(i).Tag.Get("json"), ",")[0]
+		changes = append(changes, models.FieldChange{Field: field, From: a, To: b})
+	}
+	return changes
+}
+
+// sameValue compares snapshot fields. Times are compared as instants, since
+// decoded times carry a location per value.
+func sameValue(a, b interface{}) bool {
+	if t, ok := a.(time.Time); ok {
+		return t.Equal(b.(time.Time))
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func snapshotMovie(movie models.Movie) models.MovieSnapshot {
+	genres := make([]string, len(movie.Genres))
+	for i, genre := range movie.Genres {
+		genres[i] = genre.Name
+	}
+
+	return models.MovieSnapshot{
+		Title:        movie.Title,
+		Director:     movie.Director,
+		Year:         movie.Year,
+		Plot:         movie.Plot,
+		Genres:       genres,
+		Rating:       movie.Rating,
+		UserID:       movie.UserID,
+		ModifiedByID: movie.ModifiedByID,
+		ModifiedVia:  movie.ModifiedVia,
+		CreatedAt:    movie.CreatedAt,
+		UpdatedAt:    movie.UpdatedAt,
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/config"
+	"github.com/dostonshernazarov/movies-app/repositories"
+)
+
+// RevocationStore is a denylist of access token IDs (the jti claim).
+type RevocationStore interface {
+	Revoke(tokenID string, expiresAt time.Time) error
+	IsRevoked(tokenID string) (bool, error)
+}
+
+// NewRevocationStore picks the store named by auth.revocation_store. The
+// in-memory store is only suitable for a single replica.
+func NewRevocationStore(repo *repositories.RevokedTokenRepository, cfg *config.Config) RevocationStore {
+	if cfg.Auth.RevocationStore == "memory" {
+		return NewMemoryRevocationStore()
+	}
+	return repo
+}
+
+type MemoryRevocationStore struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+}
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens: make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryRevocationStore) Revoke(tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range s.tokens {
+		if now.After(exp) {
+			delete(s.tokens, id)
+		}
+	}
+	s.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsRevoked(tokenID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exp, ok := s.tokens[tokenID]
+	return ok && time.Now().Before(exp), nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/config"
+)
+
+// TrashPurger permanently deletes movies that have stayed in the trash
+// longer than the retention period. A retention of zero days disables it.
+type TrashPurger struct {
+	MovieService *MovieService
+	Retention    time.Duration
+	Interval     time.Duration
+}
+
+func NewTrashPurger(movieService *MovieService, cfg *config.Config) *TrashPurger {
+	return &TrashPurger{
+		MovieService: movieService,
+		Retention:    time.Duration(cfg.Trash.RetentionDays) * 24 * time.Hour,
+		Interval:     cfg.Trash.PurgeInterval,
+	}
+}
+
+// Enabled reports whether trashed movies are ever purged.
+func (p *TrashPurger) Enabled() bool {
+	return p.Retention > 0
+}
+
+// PurgeAt returns when a movie deleted at deletedAt becomes due for purging.
+func (p *TrashPurger) PurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.Add(p.Retention)
+}
+
+// Run purges expired movies once per interval until ctx is cancelled.
+func (p *TrashPurger) Run(ctx context.Context) {
+	if !p.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *TrashPurger) purge() {
+	purged, err := p.MovieService.PurgeTrash(time.Now().Add(-p.Retention))
+	if err != nil {
+		log.Printf("Failed to purge trash: %v", err)
+	}
+	if purged > 0 {
+		log.Printf("Purged %d movies from the trash", purged)
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/repositories"
+	"gorm.io/gorm"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+type UserService struct {
+	UserRepo *repositories.UserRepository
+}
+
+func NewUserService(userRepo *repositories.UserRepository) *UserService {
+	return &UserService{
+		UserRepo: userRepo,
+	}
+}
+
+func (s *UserService) GetUserByID(id uint) (models.User, error) {
+	user, err := s.UserRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+func (s *UserService) GetUserByUsername(username string) (models.User, error) {
+	user, err := s.UserRepo.FindByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+func (s *UserService) UpdateRole(id uint, role string) (models.User, error) {
+	if err := s.UserRepo.UpdateRole(id, role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, ErrUserNotFound
+		}
+		return models.User{}, err
+	}
+	return s.GetUserByID(id)
+}
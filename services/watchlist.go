@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWatchlistEntryExists = errors.New("movie is already on your watchlist")
+	ErrNotOnWatchlist       = errors.New("movie is not on your watchlist")
+)
+
+type WatchlistService struct {
+	WatchlistRepo *repositories.WatchlistRepository
+	MovieRepo     *repositories.MovieRepository
+	DB            *gorm.DB
+}
+
+func NewWatchlistService(watchlistRepo *repositories.WatchlistRepository, movieRepo *repositories.MovieRepository, db *gorm.DB) *WatchlistService {
+	return &WatchlistService{
+		WatchlistRepo: watchlistRepo,
+		MovieRepo:     movieRepo,
+		DB:            db,
+	}
+}
+
+func (s *WatchlistService) ListWatchlist(userID uint, page *models.PageQuery) ([]models.WatchlistEntry, int64, error) {
+	normalizePage(page)
+	return s.WatchlistRepo.List(userID, *page)
+}
+
+// AddToWatchlist puts a movie on the user's watchlist at the given position,
+// or at the end when position is zero or past the end.
+func (s *WatchlistService) AddToWatchlist(userID, movieID uint, position int) (models.WatchlistEntry, error) {
+	movie, err := s.MovieRepo.GetByID(movieID)
+	if err != nil {
+		return models.WatchlistEntry{}, movieLookupError(err)
+	}
+
+	entry := models.WatchlistEntry{UserID: userID, MovieID: movieID}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		watchlist := s.WatchlistRepo.WithTx(tx)
+		entries, err := watchlist.LockAll(userID)
+		if err != nil {
+			return err
+		}
+
+		exists, err := watchlist.Exists(userID, movieID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrWatchlistEntryExists
+		}
+
+		index := clampPosition(position, len(entries)+1) - 1
+		entry.Position = index + 1
+		if err := watchlist.Create(&entry); err != nil {
+			return err
+		}
+		return renumberWatchlist(watchlist, insertAt(entries, index, entry))
+	})
+	if err != nil {
+		return models.WatchlistEntry{}, err
+	}
+
+	entry.Movie = movie
+	return entry, nil
+}
+
+// RemoveFromWatchlist takes a movie off the user's watchlist. Movies in the
+// trash can't be removed until they are restored.
+func (s *WatchlistService) RemoveFromWatchlist(userID, movieID uint) error {
+	if _, err := s.MovieRepo.GetByID(movieID); err != nil {
+		return movieLookupError(err)
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		watchlist := s.WatchlistRepo.WithTx(tx)
+		entries, err := watchlist.LockAll(userID)
+		if err != nil {
+			return err
+		}
+
+		index := findWatchlistEntry(entries, movieID)
+		if index < 0 {
+			return ErrNotOnWatchlist
+		}
+		if err := watchlist.Delete(entries[index].ID); err != nil {
+			return err
+		}
+		return renumberWatchlist(watchlist, append(entries[:index], entries[index+1:]...))
+	})
+}
+
+// MoveWatchlistEntry moves a movie to a new position in the user's
+// watchlist, shifting the entries in between.
+func (s *WatchlistService) MoveWatchlistEntry(userID, movieID uint, position int) (models.WatchlistEntry, error) {
+	movie, err := s.MovieRepo.GetByID(movieID)
+	if err != nil {
+		return models.WatchlistEntry{}, movieLookupError(err)
+	}
+
+	var entry models.WatchlistEntry
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		watchlist := s.WatchlistRepo.WithTx(tx)
+		entries, err := watchlist.LockAll(userID)
+		if err != nil {
+			return err
+		}
+
+		index := findWatchlistEntry(entries, movieID)
+		if index < 0 {
+			return ErrNotOnWatchlist
+		}
+		entry = entries[index]
+
+		entries = append(entries[:index], entries[index+1:]...)
+		target := clampPosition(position, len(entries)+1) - 1
+		entries = insertAt(entries, target, entry)
+		entry.Position = target + 1
+		return renumberWatchlist(watchlist, entries)
+	})
+	if err != nil {
+		return models.WatchlistEntry{}, err
+	}
+
+	entry.Movie = movie
+	return entry, nil
+}
+
+func findWatchlistEntry(entries []models.WatchlistEntry, movieID uint) int {
+	for i, entry := range entries {
+		if entry.MovieID == movieID {
+			return i
+		}
+	}
+	return -1
+}
+
+// renumberWatchlist stores 1-based positions following the slice order,
+// touching only the entries whose position changed.
+func renumberWatchlist(watchlist *repositories.WatchlistRepository, entries []models.WatchlistEntry) error {
+	for i, entry := range entries {
+		if entry.Position == i+1 {
+			continue
+		}
+		if err := watchlist.UpdatePosition(entry.ID, i+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
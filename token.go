@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dostonshernazarov/movies-app/config"
+	"github.com/dostonshernazarov/movies-app/core"
+	"github.com/dostonshernazarov/movies-app/services"
+)
+
+const tokenUsage = `usage: movies-app token issue -username <name> [-ttl <duration>]
+
+Prints an access token for a service account. The token has no refresh
+token and stays valid for -ttl, unless the user's sessions are revoked.`
+
+func runToken(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "issue" {
+		fmt.Fprintln(os.Stderr, tokenUsage)
+		if len(args) == 0 {
+			return errors.New("missing token command")
+		}
+		return fmt.Errorf("unknown token command %q", args[0])
+	}
+
+	flags := newFlagSet("token issue", tokenUsage)
+	username := flags.String("username", "", "username of the service account")
+	ttl := flags.Duration("ttl", 30*24*time.Hour, "how long the token stays valid")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *username == "" {
+		flags.Usage()
+		return errors.New("-username is required")
+	}
+	if *ttl <= 0 {
+		return errors.New("-ttl must be positive")
+	}
+
+	return core.Run(cfg, func(users *services.UserService, auth *services.AuthService) error {
+		user, err := users.GetUserByUsername(*username)
+		if err != nil {
+			return err
+		}
+
+		token, expiresAt, err := auth.IssueServiceToken(user, *ttl)
+		if err != nil {
+			return err
+		}
+
+		// Only the token goes to standard output, so it can be captured.
+		fmt.Fprintf(os.Stderr, "Token for %s (%s) expires at %s\n", user.Username, user.Role, expiresAt.Format(time.RFC3339))
+		fmt.Println(token)
+		return nil
+	})
+}
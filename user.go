@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dostonshernazarov/movies-app/config"
+	"github.com/dostonshernazarov/movies-app/core"
+	"github.com/dostonshernazarov/movies-app/models"
+	"github.com/dostonshernazarov/movies-app/services"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const userUsage = `usage: movies-app user <command> [flags]
+
+commands:
+  create          add a user account whose email counts as verified
+  reset-password  set a user's password and sign them out everywhere`
+
+// minPasswordLength matches the validation of the registration API.
+const minPasswordLength = 6
+
+func runUser(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, userUsage)
+		return errors.New("missing user command")
+	}
+
+	switch args[0] {
+	case "create":
+		return runUserCreate(cfg, args[1:])
+	case "reset-password":
+		return runUserResetPassword(cfg, args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Println(userUsage)
+		return nil
+	default:
+		fmt.Fprintln(os.Stderr, userUsage)
+		return fmt.Errorf("unknown user command %q", args[0])
+	}
+}
+
+func runUserCreate(cfg *config.Config, args []string) error {
+	flags := newFlagSet("user create", `usage: movies-app user create -username <name> -email <address> [-role <role>] [-password-stdin]
+
+Without -password-stdin a random password is generated and printed.`)
+	username := flags.String("username", "", "username of the new user")
+	email := flags.String("email", "", "email address of the new user")
+	role := flags.String("role", models.RoleUser, "role: user, moderator or admin")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from standard input")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	password, generated, err := readPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	request := models.UserRegisterRequest{Username: *username, Password: password, Email: *email}
+	if err := binding.Validator.ValidateStruct(&request); err != nil {
+		return err
+	}
+	if err := binding.Validator.ValidateStruct(&models.UserRoleRequest{Role: *role}); err != nil {
+		return err
+	}
+
+	return core.Run(cfg, func(auth *services.AuthService) error {
+		user := models.User{
+			Username: request.Username,
+			Password: request.Password,
+			Email:    request.Email,
+			Role:     *role,
+		}
+		if err := auth.CreateUser(&user); err != nil {
+			return err
+		}
+
+		fmt.Printf("Created %s %s with ID %d\n", user.Role, user.Username, user.ID)
+		if generated {
+			fmt.Printf("Password: %s\n", password)
+		}
+		return nil
+	})
+}
+
+func runUserResetPassword(cfg *config.Config, args []string) error {
+	flags := newFlagSet("user reset-password", `usage: movies-app user reset-password -username <name> [-password-stdin]
+
+Sets a new password and revokes every session of the user. Without
+-password-stdin a random password is generated and printed.`)
+	username := flags.String("username", "", "username of the user")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from standard input")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		flags.Usage()
+		return errors.New("-username is required")
+	}
+
+	password, generated, err := readPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	return core.Run(cfg, func(users *services.UserService, auth *services.AuthService) error {
+		user, err := users.GetUserByUsername(*username)
+		if err != nil {
+			return err
+		}
+		if err := auth.SetPassword(user.ID, password); err != nil {
+			return err
+		}
+
+		fmt.Printf("Changed the password of %s and revoked their sessions\n", user.Username)
+		if generated {
+			fmt.Printf("Password: %s\n", password)
+		}
+		return nil
+	})
+}
+
+// readPassword reads a password from the first line of standard input, so it
+// stays out of the shell history, or generates one. It reports whether the
+// password was generated.
+func readPassword(fromStdin bool) (string, bool, error) {
+	if fromStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", false, fmt.Errorf("failed to read password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), false, nil
+	}
+
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), true, nil
+}